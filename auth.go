@@ -0,0 +1,161 @@
+package tapestry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	apiKeyHeader     = "X-Api-Key"
+	apiKeyQueryParam = "apiKey"
+	redacted         = "REDACTED"
+)
+
+// APIKeyMode selects how the API key is sent to the server.
+type APIKeyMode int
+
+const (
+	// APIKeyAuto sends the key in the X-Api-Key header. If the server rejects
+	// the header with 401, the request is repeated with the key in the query
+	// string and the client keeps using the query string afterwards.
+	APIKeyAuto APIKeyMode = iota
+	// APIKeyHeader always sends the key in the X-Api-Key header.
+	APIKeyHeader
+	// APIKeyQuery always sends the key as the apiKey query parameter.
+	APIKeyQuery
+)
+
+// apiKeyState remembers whether APIKeyAuto fell back to the query string. It
+// is shared between copies of a client.
+type apiKeyState struct {
+	queryFallback int32
+}
+
+// SetAPIKeyMode selects how the API key is sent. It must be called before the
+// client is used concurrently.
+func (c *TapestryClient) SetAPIKeyMode(mode APIKeyMode) {
+	c.apiKeyMode = mode
+}
+
+func (c *TapestryClient) apiKeyInHeader() bool {
+	switch c.apiKeyMode {
+	case APIKeyHeader:
+		return true
+	case APIKeyQuery:
+		return false
+	}
+	return c.apiKeyState == nil || atomic.LoadInt32(&c.apiKeyState.queryFallback) == 0
+}
+
+// authorize attaches the API key of the call to req, either as a header or
+// in the query string.
+func (c *TapestryClient) authorize(req *http.Request, inHeader bool) {
+	apiKey := c.apiKeyFor(req.Context())
+	if apiKey == "" {
+		return
+	}
+	query := req.URL.Query()
+	if inHeader {
+		req.Header.Set(apiKeyHeader, apiKey)
+		query.Del(apiKeyQueryParam)
+	} else {
+		req.Header.Del(apiKeyHeader)
+		query.Set(apiKeyQueryParam, apiKey)
+	}
+	req.URL.RawQuery = query.Encode()
+}
+
+// doAuthorized sends a copy of req with the API key attached, leaving req
+// itself without it. In APIKeyAuto mode a 401 answer to a header-authenticated
+// request is repeated once with the key in the query string.
+func (c *TapestryClient) doAuthorized(req *http.Request) (*http.Response, error) {
+	inHeader := c.apiKeyInHeader()
+	req = req.Clone(req.Context())
+	c.authorize(req, inHeader)
+
+	ctx := req.Context()
+	resp, err := c.doDumped(req)
+	if err != nil {
+		return nil, c.redactError(ctx, err)
+	}
+	if !inHeader || c.apiKeyMode != APIKeyAuto || c.apiKeyFor(ctx) == "" || resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	next, err := rewind(req)
+	if err != nil {
+		// the body cannot be replayed, report the original answer
+		return resp, nil
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+	resp.Body.Close()
+
+	c.authorize(next, false)
+	resp, err = c.doDumped(next)
+	if err != nil {
+		return nil, c.redactError(ctx, err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized && c.apiKeyState != nil {
+		if atomic.CompareAndSwapInt32(&c.apiKeyState.queryFallback, 0, 1) && c.logger != nil {
+			c.logger.InfoContext(ctx, "tapestry API key header rejected, sending the key in the query string")
+		}
+	}
+	return resp, nil
+}
+
+// redactKeys returns the API keys a call made with ctx may send: the
+// client's and the one set with WithCallAPIKey.
+func (c *TapestryClient) redactKeys(ctx context.Context) []string {
+	var keys []string
+	for _, key := range []string{c.apiKeyFor(ctx), c.apiKey} {
+		if key != "" && (len(keys) == 0 || keys[0] != key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// redact replaces every occurrence of the API keys of ctx in s.
+func (c *TapestryClient) redact(ctx context.Context, s string) string {
+	for _, key := range c.redactKeys(ctx) {
+		s = strings.ReplaceAll(s, key, redacted)
+		if escaped := url.QueryEscape(key); escaped != key {
+			s = strings.ReplaceAll(s, escaped, redacted)
+		}
+	}
+	return s
+}
+
+// redactError strips the API keys of ctx from transport errors, which
+// usually embed the request URL.
+func (c *TapestryClient) redactError(ctx context.Context, err error) error {
+	keys := c.redactKeys(ctx)
+	if len(keys) == 0 {
+		return err
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = c.redact(ctx, urlErr.URL)
+	}
+	for _, key := range keys {
+		if strings.Contains(err.Error(), key) || strings.Contains(err.Error(), url.QueryEscape(key)) {
+			return &redactedError{err: err, message: c.redact(ctx, err.Error())}
+		}
+	}
+	return err
+}
+
+// redactedError hides the API key from the message of an error it wraps.
+type redactedError struct {
+	err     error
+	message string
+}
+
+func (e *redactedError) Error() string { return e.message }
+
+func (e *redactedError) Unwrap() error { return e.err }
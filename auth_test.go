@@ -0,0 +1,128 @@
+package tapestry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const testAPIKey = "secret-key/+="
+
+func TestAPIKey_SentInHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get(apiKeyHeader); got != testAPIKey {
+			t.Errorf("header %s = %q, want %q", apiKeyHeader, got, testAPIKey)
+		}
+		if r.URL.Query().Has(apiKeyQueryParam) {
+			t.Errorf("query %q carries the API key", r.URL.RawQuery)
+		}
+		if got := r.URL.Query().Get("username"); got != "user name" {
+			t.Errorf("username = %q, want %q", got, "user name")
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewTapestryClientWithHTTPClient(testAPIKey, server.URL, ExecutionFastUnconfirmed, "SOLANA", server.Client())
+	if err := client.CreateLike(context.Background(), "c1", Profile{ID: "p1", Username: "user name"}); err != nil {
+		t.Fatalf("CreateLike() error = %v", err)
+	}
+}
+
+func TestAPIKey_AutoFallsBackToQuery(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Query().Get(apiKeyQueryParam) != testAPIKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(apiKeyHeader) != "" {
+			t.Errorf("fallback request still carries the %s header", apiKeyHeader)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewTapestryClientWithHTTPClient(testAPIKey, server.URL, ExecutionFastUnconfirmed, "SOLANA", server.Client())
+	ctx := context.Background()
+
+	if err := client.AddFollower(ctx, "a", "b"); err != nil {
+		t.Fatalf("first AddFollower() error = %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("first call made %d requests, want 2", calls)
+	}
+	if err := client.AddFollower(ctx, "a", "b"); err != nil {
+		t.Fatalf("second AddFollower() error = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("second call made %d requests, want 1 after fallback", calls-2)
+	}
+}
+
+func TestAPIKey_HeaderModeDoesNotFallBack(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewTapestryClientWithHTTPClient(testAPIKey, server.URL, ExecutionFastUnconfirmed, "SOLANA", server.Client())
+	client.SetAPIKeyMode(APIKeyHeader)
+
+	if _, err := client.GetProfileByID(context.Background(), "p1"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("GetProfileByID() error = %v, want ErrUnauthorized", err)
+	}
+	if calls != 1 {
+		t.Errorf("made %d requests, want 1", calls)
+	}
+}
+
+type failingDoer struct{}
+
+func (failingDoer) Do(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("dial failed for %s", req.URL)
+}
+
+func TestAPIKey_RedactedFromErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closedURL := server.URL
+	server.Close()
+
+	doers := map[string]HTTPDoer{
+		"http.Client": &http.Client{},
+		"custom":      failingDoer{},
+	}
+	for name, doer := range doers {
+		for _, mode := range []APIKeyMode{APIKeyAuto, APIKeyQuery} {
+			client := NewTapestryClientWithHTTPClient(testAPIKey, closedURL, ExecutionFastUnconfirmed, "SOLANA", doer)
+			client.SetAPIKeyMode(mode)
+			ctx := context.Background()
+
+			errs := []error{
+				client.DeleteContent(ctx, "c1"),
+				client.CreateLike(ctx, "c1", Profile{ID: "p1", Username: "u"}),
+			}
+			_, err := client.GetComments(ctx, GetCommentsOptions{ContentID: "c1"})
+			errs = append(errs, err)
+			_, err = client.GetContents(ctx, WithPagination("1", "10"))
+			errs = append(errs, err)
+
+			for _, err := range errs {
+				if err == nil {
+					t.Fatalf("%s/%d: expected an error from a closed server", name, mode)
+				}
+				if strings.Contains(err.Error(), "secret-key") {
+					t.Errorf("%s/%d: error leaks the API key: %v", name, mode, err)
+				}
+			}
+		}
+	}
+}
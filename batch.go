@@ -0,0 +1,122 @@
+package tapestry
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	defaultBatchChunkSize   = 100
+	defaultBatchConcurrency = 4
+)
+
+// BatchReadOptions controls how GetContentsByBatchIDs splits large ID lists.
+type BatchReadOptions struct {
+	// ChunkSize is the largest number of IDs sent in one request. Defaults
+	// to 100.
+	ChunkSize int
+	// Concurrency is the number of chunk requests in flight at once.
+	// Defaults to 4.
+	Concurrency int
+}
+
+func (o BatchReadOptions) chunkSize() int {
+	if o.ChunkSize > 0 {
+		return o.ChunkSize
+	}
+	return defaultBatchChunkSize
+}
+
+func (o BatchReadOptions) concurrency() int {
+	if o.Concurrency > 0 {
+		return o.Concurrency
+	}
+	return defaultBatchConcurrency
+}
+
+// WithBatchReads sets how GetContentsByBatchIDs splits large ID lists into
+// concurrent requests.
+func WithBatchReads(opts BatchReadOptions) ClientOption {
+	return func(c *TapestryClient) {
+		c.batchReads = opts
+	}
+}
+
+// uniqueIDs returns ids without repetitions, in order of first appearance.
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// readContentBatches reads ids in chunks of at most ChunkSize, with at most
+// Concurrency requests in flight. The first failing chunk cancels the others
+// and its error is returned.
+func (c *TapestryClient) readContentBatches(ctx context.Context, ids []string) ([]GetContentsByBatchIDsResponse, error) {
+	uri := c.endpoint(nil, "contents", "batch", "read")
+
+	size := c.batchReads.chunkSize()
+	var chunks [][]string
+	for start := 0; start < len(ids); start += size {
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunks = append(chunks, ids[start:end])
+	}
+
+	responses := make([]GetContentsByBatchIDsResponse, len(chunks))
+	if len(chunks) == 1 {
+		if err := c.doJSON(ctx, opGetContentsByBatchIDs, uri, chunks[0], &responses[0]); err != nil {
+			return nil, err
+		}
+		return responses, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	sem := make(chan struct{}, c.batchReads.concurrency())
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk []string) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				fail(ctx.Err())
+				return
+			}
+			defer func() { <-sem }()
+
+			if err := c.doJSON(ctx, opGetContentsByBatchIDs, uri, chunk, &responses[i]); err != nil {
+				fail(err)
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return responses, nil
+}
@@ -0,0 +1,152 @@
+package tapestry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetContentsByBatchIDs_ChunksAndMerges(t *testing.T) {
+	server := newBatchServer(t)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()),
+		WithBatchReads(BatchReadOptions{ChunkSize: 10}))
+
+	var ids, wantSuccessful, wantFailed []string
+	for i := 24; i >= 0; i-- {
+		id := fmt.Sprintf("c%02d", i)
+		if i%5 == 0 {
+			id = fmt.Sprintf("bad%02d", i)
+			wantFailed = append(wantFailed, id)
+		} else {
+			wantSuccessful = append(wantSuccessful, id)
+		}
+		ids = append(ids, id)
+	}
+	// repeated IDs are only read once
+	ids = append(ids, ids[:5]...)
+
+	resp, err := client.GetContentsByBatchIDs(context.Background(), ids)
+	if err != nil {
+		t.Fatalf("GetContentsByBatchIDs() error = %v", err)
+	}
+
+	batches := server.batchIDs(t)
+	if len(batches) != 3 {
+		t.Errorf("sent %d requests, want 3", len(batches))
+	}
+	for _, batch := range batches {
+		if len(batch) > 10 {
+			t.Errorf("batch of %d IDs exceeds the chunk size", len(batch))
+		}
+	}
+
+	var successful, failed []string
+	for _, item := range resp.Successful {
+		successful = append(successful, item.Content.ID)
+	}
+	for _, item := range resp.Failed {
+		failed = append(failed, item.ID)
+	}
+	if strings.Join(successful, ",") != strings.Join(wantSuccessful, ",") {
+		t.Errorf("Successful = %v, want %v", successful, wantSuccessful)
+	}
+	if strings.Join(failed, ",") != strings.Join(wantFailed, ",") {
+		t.Errorf("Failed = %v, want %v", failed, wantFailed)
+	}
+}
+
+func TestGetContentsByBatchIDs_KeepsUnexpectedAnswers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// "b" is left out, and "c" comes back under another ID
+		_ = json.NewEncoder(w).Encode(GetContentsByBatchIDsResponse{
+			Successful: []BatchResponseContentListItem{{Content: Content{ID: "C"}}, {Content: Content{ID: "a"}}},
+		})
+	}))
+	defer server.Close()
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+
+	resp, err := client.GetContentsByBatchIDs(context.Background(), []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("GetContentsByBatchIDs() error = %v", err)
+	}
+
+	var successful, failed []string
+	for _, item := range resp.Successful {
+		successful = append(successful, item.Content.ID)
+	}
+	for _, item := range resp.Failed {
+		failed = append(failed, item.ID+":"+item.Error)
+	}
+	if strings.Join(successful, ",") != "a,C" {
+		t.Errorf("Successful = %v, want a,C", successful)
+	}
+	if strings.Join(failed, ",") != "b:not returned by the API,c:not returned by the API" {
+		t.Errorf("Failed = %v, want b and c not returned", failed)
+	}
+}
+
+func TestGetContentsByBatchIDs_BoundedConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"successful":[],"failed":[]}`))
+	}))
+	defer server.Close()
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()),
+		WithBatchReads(BatchReadOptions{ChunkSize: 2, Concurrency: 3}))
+
+	ids := make([]string, 40)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i)
+	}
+	if _, err := client.GetContentsByBatchIDs(context.Background(), ids); err != nil {
+		t.Fatalf("GetContentsByBatchIDs() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got > 3 || got < 2 {
+		t.Errorf("max requests in flight = %d, want 2 or 3", got)
+	}
+}
+
+func TestGetContentsByBatchIDs_FailingChunk(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ids []string
+		_ = json.NewDecoder(r.Body).Decode(&ids)
+		if ids[0] == "0" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()),
+		WithBatchReads(BatchReadOptions{ChunkSize: 1, Concurrency: 4}))
+
+	start := time.Now()
+	_, err := client.GetContentsByBatchIDs(context.Background(), []string{"1", "0", "2", "3"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("GetContentsByBatchIDs() error = %v, want the failing chunk's 400", err)
+	}
+	if time.Since(start) > 4*time.Second {
+		t.Errorf("the other chunks were not canceled after the failure")
+	}
+}
@@ -0,0 +1,295 @@
+package tapestry
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Cache stores encoded API responses for the read-through cache enabled by
+// WithCache. Implementations must be safe for concurrent use and decide
+// themselves when entries expire.
+//
+// Keys identify a profile, content or comment by ID, prefixed with a hash of
+// the API key and base URL when a call overrides them with WithCallAPIKey or
+// WithCallBaseURL. Share a Cache only between clients that use the same API
+// key and base URL.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+	Delete(key string)
+}
+
+// WithCache serves GetProfileByID, GetContentByID, GetCommentByID and
+// GetContentsByBatchIDs from cache when possible. Writes through the client
+// invalidate the entries they affect, including the social counts of the
+// content a comment or like belongs to. DeleteComment first reads a comment
+// that is not cached to learn its content. Changes made elsewhere are only
+// seen once entries expire.
+func WithCache(cache Cache) ClientOption {
+	return func(c *TapestryClient) {
+		c.cache = cache
+	}
+}
+
+func profileCacheKey(id string) string {
+	return "profile:" + id
+}
+
+func contentCacheKey(id string) string {
+	return "content:" + id
+}
+
+func commentCacheKey(id string) string {
+	return "comment:" + id
+}
+
+// cacheGet decodes the entry for key into out and reports whether there was
+// one.
+func (c *TapestryClient) cacheGet(ctx context.Context, key string, out any) bool {
+	if c.cache == nil {
+		return false
+	}
+
+	key = c.scopedKey(ctx, key)
+	data, ok := c.cache.Get(key)
+	if !ok {
+		return false
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		c.cache.Delete(key)
+		return false
+	}
+	return true
+}
+
+// cacheFills tracks the keys being read through the cache, so that a read
+// that overlapped a write to the same key does not store what it read before
+// the write invalidated the key.
+type cacheFills struct {
+	mu   sync.Mutex
+	keys map[string]*cacheFillKey
+}
+
+type cacheFillKey struct {
+	generation uint64
+	readers    int
+}
+
+// cacheFill is one read through the cache of a key, started by startFill.
+// Finish it with done, once set has been called or the read failed.
+type cacheFill struct {
+	c          *TapestryClient
+	key        string
+	generation uint64
+}
+
+// startFill starts a read of key that may then be stored with set. It returns
+// nil, which set and done accept, when there is no cache.
+func (c *TapestryClient) startFill(ctx context.Context, key string) *cacheFill {
+	if c.cache == nil {
+		return nil
+	}
+
+	f := &cacheFill{c: c, key: c.scopedKey(ctx, key)}
+	if fills := c.cacheFills; fills != nil {
+		fills.mu.Lock()
+		if fills.keys == nil {
+			fills.keys = make(map[string]*cacheFillKey)
+		}
+		k, ok := fills.keys[f.key]
+		if !ok {
+			k = &cacheFillKey{}
+			fills.keys[f.key] = k
+		}
+		k.readers++
+		f.generation = k.generation
+		fills.mu.Unlock()
+	}
+	return f
+}
+
+// set stores value unless the key was invalidated since the read started.
+func (f *cacheFill) set(value any) {
+	if f == nil {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	fills := f.c.cacheFills
+	if fills == nil {
+		f.c.cache.Set(f.key, data)
+		return
+	}
+
+	// holding the lock while setting orders the entry before the delete of
+	// any invalidate that has not bumped the generation yet
+	fills.mu.Lock()
+	defer fills.mu.Unlock()
+	if fills.keys[f.key].generation == f.generation {
+		f.c.cache.Set(f.key, data)
+	}
+}
+
+// done ends the read, forgetting the key once no read of it is left.
+func (f *cacheFill) done() {
+	if f == nil || f.c.cacheFills == nil {
+		return
+	}
+
+	fills := f.c.cacheFills
+	fills.mu.Lock()
+	defer fills.mu.Unlock()
+	k := fills.keys[f.key]
+	k.readers--
+	if k.readers == 0 {
+		delete(fills.keys, f.key)
+	}
+}
+
+// invalidate drops the entries for keys and keeps reads already under way
+// from storing them again. Writes call it whether or not they succeeded,
+// since a failed write may still have been applied.
+func (c *TapestryClient) invalidate(ctx context.Context, keys ...string) {
+	if c.cache == nil {
+		return
+	}
+
+	for _, key := range keys {
+		key = c.scopedKey(ctx, key)
+		if fills := c.cacheFills; fills != nil {
+			fills.mu.Lock()
+			if k, ok := fills.keys[key]; ok {
+				k.generation++
+			}
+			fills.mu.Unlock()
+		}
+		c.cache.Delete(key)
+	}
+}
+
+// commentContentID returns the ID of the content commentID belongs to, read
+// from cache or else from the API. It is empty without a cache or when the
+// comment cannot be read.
+func (c *TapestryClient) commentContentID(ctx context.Context, commentID string) string {
+	if c.cache == nil {
+		return ""
+	}
+
+	var comment GetCommentByIdResponse
+	if c.cacheGet(ctx, commentCacheKey(commentID), &comment) && comment.ContentID != "" {
+		return comment.ContentID
+	}
+	comment, err := c.fetchComment(ctx, commentID)
+	if err != nil {
+		return ""
+	}
+	return comment.ContentID
+}
+
+// invalidateComment drops commentID and, when known, the content it belongs
+// to.
+func (c *TapestryClient) invalidateComment(ctx context.Context, commentID, contentID string) {
+	if contentID != "" {
+		c.invalidate(ctx, contentCacheKey(contentID))
+	}
+	c.invalidate(ctx, commentCacheKey(commentID))
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used entry
+// once it holds size entries, and expires entries ttl after they were set.
+type MemoryCache struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns a MemoryCache. A size of 0 or less does not bound
+// the number of entries, and a ttl of 0 or less keeps them until evicted.
+func NewMemoryCache(size int, ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*memoryCacheEntry)
+	if !entry.expires.IsZero() && !m.now().Before(entry.expires) {
+		m.remove(elem)
+		return nil, false
+	}
+
+	m.lru.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (m *MemoryCache) Set(key string, value []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expires time.Time
+	if m.ttl > 0 {
+		expires = m.now().Add(m.ttl)
+	}
+
+	if elem, ok := m.entries[key]; ok {
+		entry := elem.Value.(*memoryCacheEntry)
+		entry.value, entry.expires = value, expires
+		m.lru.MoveToFront(elem)
+		return
+	}
+
+	m.entries[key] = m.lru.PushFront(&memoryCacheEntry{key: key, value: value, expires: expires})
+	if m.size > 0 && m.lru.Len() > m.size {
+		m.remove(m.lru.Back())
+	}
+}
+
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.entries[key]; ok {
+		m.remove(elem)
+	}
+}
+
+// Len returns the number of entries, including expired ones not yet removed.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.lru.Len()
+}
+
+func (m *MemoryCache) remove(elem *list.Element) {
+	m.lru.Remove(elem)
+	delete(m.entries, elem.Value.(*memoryCacheEntry).key)
+}
@@ -0,0 +1,260 @@
+package tapestry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewMemoryCache(2, 0)
+	cache.Set("a", []byte("1"))
+	cache.Set("b", []byte("2"))
+	cache.Get("a")
+	cache.Set("c", []byte("3"))
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("least recently used entry was not evicted")
+	}
+	if v, ok := cache.Get("a"); !ok || string(v) != "1" {
+		t.Errorf("Get(a) = %q, %v", v, ok)
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", cache.Len())
+	}
+
+	cache.Delete("a")
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("deleted entry is still cached")
+	}
+}
+
+func TestMemoryCache_Expires(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := NewMemoryCache(0, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("a", []byte("1"))
+	now = now.Add(59 * time.Second)
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatalf("entry expired before its ttl")
+	}
+	now = now.Add(time.Second)
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("entry did not expire after its ttl")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("expired entry was not removed")
+	}
+}
+
+// newCacheTestServer answers reads with fixed entities.
+func newCacheTestServer(t *testing.T) *recordingServer {
+	return newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/contents/batch/read":
+			var ids []string
+			_ = json.NewDecoder(r.Body).Decode(&ids)
+			var resp GetContentsByBatchIDsResponse
+			for _, id := range ids {
+				resp.Successful = append(resp.Successful, BatchResponseContentListItem{Content: Content{ID: id}})
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/contents/"):
+			id := strings.TrimPrefix(r.URL.Path, "/contents/")
+			_ = json.NewEncoder(w).Encode(GetContentResponse{Content: Content{ID: id}, SocialCounts: SocialCounts{LikeCount: 1}})
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/comments/"):
+			_ = json.NewEncoder(w).Encode(GetCommentByIdResponse{CommentData{ContentID: "c1", Comment: Comment{ID: "m1"}}})
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/profiles/"):
+			_ = json.NewEncoder(w).Encode(ProfileResponse{Profile: Profile{ID: strings.TrimPrefix(r.URL.Path, "/profiles/")}})
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	})
+}
+
+func TestCache_ReadThroughAndInvalidation(t *testing.T) {
+	server := newCacheTestServer(t)
+	client, err := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithCache(NewMemoryCache(100, time.Minute)))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+	profile := Profile{ID: "p1", Username: "alice"}
+
+	steps := []struct {
+		name     string
+		write    func() error
+		read     func() error
+		readPath string
+	}{
+		{
+			name:     "profile",
+			write:    func() error { return client.UpdateProfile(ctx, "p1", UpdateProfileParameters{Username: "alice"}) },
+			read:     func() error { _, err := client.GetProfileByID(ctx, "p1"); return err },
+			readPath: "/profiles/p1",
+		},
+		{
+			name:     "content after like",
+			write:    func() error { return client.CreateLike(ctx, "c1", profile) },
+			read:     func() error { _, err := client.GetContentByID(ctx, "c1"); return err },
+			readPath: "/contents/c1",
+		},
+		{
+			name: "content after comment",
+			write: func() error {
+				_, err := client.CreateComment(ctx, CreateCommentOptions{ContentID: "c1", ProfileID: "p1", Text: "hi"})
+				return err
+			},
+			read:     func() error { _, err := client.GetContentByID(ctx, "c1"); return err },
+			readPath: "/contents/c1",
+		},
+		{
+			name:     "comment",
+			write:    func() error { _, err := client.UpdateComment(ctx, "m1", nil); return err },
+			read:     func() error { _, err := client.GetCommentByID(ctx, "m1", ""); return err },
+			readPath: "/comments/m1",
+		},
+	}
+
+	for _, step := range steps {
+		if err := step.read(); err != nil {
+			t.Fatalf("%s: read error = %v", step.name, err)
+		}
+		before := server.countOf(http.MethodGet, step.readPath)
+		if err := step.read(); err != nil {
+			t.Fatalf("%s: read error = %v", step.name, err)
+		}
+		if server.countOf(http.MethodGet, step.readPath) != before {
+			t.Errorf("%s: cached read was sent to the API", step.name)
+		}
+
+		if err := step.write(); err != nil {
+			t.Fatalf("%s: write error = %v", step.name, err)
+		}
+		for i := 0; i < 2; i++ {
+			if err := step.read(); err != nil {
+				t.Fatalf("%s: read error = %v", step.name, err)
+			}
+		}
+		if got := server.countOf(http.MethodGet, step.readPath) - before; got != 1 {
+			t.Errorf("%s: %d requests after the write, want 1", step.name, got)
+		}
+	}
+}
+
+func TestCache_DeleteCommentInvalidatesParentContent(t *testing.T) {
+	server := newCacheTestServer(t)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithCache(NewMemoryCache(100, time.Minute)))
+	ctx := context.Background()
+
+	_, _ = client.GetCommentByID(ctx, "m1", "")
+	_, _ = client.GetContentByID(ctx, "c1")
+	if err := client.DeleteComment(ctx, "m1"); err != nil {
+		t.Fatalf("DeleteComment() error = %v", err)
+	}
+	_, _ = client.GetContentByID(ctx, "c1")
+
+	if got := server.countOf(http.MethodGet, "/contents/c1"); got != 2 {
+		t.Errorf("content fetched %d times, want 2", got)
+	}
+}
+
+func TestCache_DeleteUncachedCommentInvalidatesParentContent(t *testing.T) {
+	server := newCacheTestServer(t)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithCache(NewMemoryCache(100, time.Minute)))
+	ctx := context.Background()
+
+	_, _ = client.GetContentByID(ctx, "c1")
+	if err := client.DeleteComment(ctx, "m1"); err != nil {
+		t.Fatalf("DeleteComment() error = %v", err)
+	}
+	_, _ = client.GetContentByID(ctx, "c1")
+
+	if got := server.countOf(http.MethodGet, "/comments/m1"); got != 1 {
+		t.Errorf("comment read %d times, want 1", got)
+	}
+	if got := server.countOf(http.MethodGet, "/contents/c1"); got != 2 {
+		t.Errorf("content fetched %d times, want 2", got)
+	}
+}
+
+func TestCache_BatchRequestsOnlyMissingIDs(t *testing.T) {
+	server := newCacheTestServer(t)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithCache(NewMemoryCache(100, time.Minute)))
+	ctx := context.Background()
+
+	if _, err := client.GetContentByID(ctx, "b"); err != nil {
+		t.Fatalf("GetContentByID() error = %v", err)
+	}
+	resp, err := client.GetContentsByBatchIDs(ctx, []string{"a", "b", "c", "a"})
+	if err != nil {
+		t.Fatalf("GetContentsByBatchIDs() error = %v", err)
+	}
+
+	var ids []string
+	for _, item := range resp.Successful {
+		ids = append(ids, item.Content.ID)
+	}
+	if strings.Join(ids, ",") != "a,b,c" {
+		t.Errorf("Successful = %v, want a,b,c in request order", ids)
+	}
+	if resp.Successful[1].SocialCounts.LikeCount != 1 {
+		t.Errorf("cached content b was not used: %+v", resp.Successful[1])
+	}
+
+	if _, err := client.GetContentsByBatchIDs(ctx, []string{"c", "a"}); err != nil {
+		t.Fatalf("GetContentsByBatchIDs() error = %v", err)
+	}
+	if batches := server.batchIDs(t); len(batches) != 1 || strings.Join(batches[0], ",") != "a,c" {
+		t.Errorf("batch requests = %v, want only [a c]", batches)
+	}
+}
+
+func TestCache_ReadRacingWriteIsNotStored(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var server *recordingServer
+	server = newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		n := server.countOf(http.MethodGet, r.URL.Path)
+		if n == 1 {
+			// hold the first read until the write has gone through
+			close(started)
+			<-release
+		}
+		_ = json.NewEncoder(w).Encode(GetContentResponse{Content: Content{ID: "c1"}, SocialCounts: SocialCounts{LikeCount: n}})
+	})
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithCache(NewMemoryCache(100, time.Minute)))
+	ctx := context.Background()
+
+	done := make(chan error)
+	go func() {
+		_, err := client.GetContentByID(ctx, "c1")
+		done <- err
+	}()
+	<-started
+	if err := client.CreateLike(ctx, "c1", Profile{ID: "p1", Username: "alice"}); err != nil {
+		t.Fatalf("CreateLike() error = %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("GetContentByID() error = %v", err)
+	}
+
+	content, err := client.GetContentByID(ctx, "c1")
+	if err != nil {
+		t.Fatalf("GetContentByID() error = %v", err)
+	}
+	if content.SocialCounts.LikeCount != 2 {
+		t.Errorf("like count = %d, want 2: the read that raced the like was cached", content.SocialCounts.LikeCount)
+	}
+	if client.cacheFills.keys[client.scopedKey(ctx, contentCacheKey("c1"))] != nil {
+		t.Errorf("finished reads are still tracked")
+	}
+}
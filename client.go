@@ -1,10 +1,48 @@
 package tapestry
 
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPDoer is the transport used to send requests to the Tapestry API.
+// *http.Client satisfies it, so callers can pass a client with their own
+// timeouts, proxies, TLS configuration or connection pool.
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
 type TapestryClient struct {
 	tapestryApiBaseUrl string
 	apiKey             string
 	execution          Execution
-	blockchain         string
+	blockchain         Blockchain
+	httpClient         HTTPDoer
+	retryPolicy        RetryPolicy
+	rateLimiter        *RateLimiter
+	apiKeyMode         APIKeyMode
+	apiKeyState        *apiKeyState
+	userAgent          string
+	timeout            time.Duration
+	logger             *slog.Logger
+	hooks              Hooks
+	interceptors       []Interceptor
+	metrics            Metrics
+	debugDumps         bool
+	cache              Cache
+	cacheFills         *cacheFills
+	flights            *flightGroup
+	contentLoader      *ContentLoader
+	batchReads         BatchReadOptions
+	waitPolicy         RetryPolicy
 }
 
 type Execution string
@@ -15,11 +53,146 @@ const (
 	ExecutionConfirmedParsed Execution = "CONFIRMED_AND_PARSED"
 )
 
+// NewTapestryClient creates a client with positional settings. New offers the
+// same and more through options.
 func NewTapestryClient(apiKey string, tapestryApiBaseUrl string, execution Execution, blockchain string) TapestryClient {
-	return TapestryClient{
-		tapestryApiBaseUrl: tapestryApiBaseUrl,
-		apiKey:             apiKey,
-		execution:          execution,
-		blockchain:         blockchain,
+	return NewTapestryClientWithHTTPClient(apiKey, tapestryApiBaseUrl, execution, blockchain, http.DefaultClient)
+}
+
+// NewTapestryClientWithHTTPClient is like NewTapestryClient but sends every
+// request through httpClient. A nil httpClient falls back to http.DefaultClient.
+func NewTapestryClientWithHTTPClient(apiKey string, tapestryApiBaseUrl string, execution Execution, blockchain string, httpClient HTTPDoer) TapestryClient {
+	return *newClient(apiKey,
+		WithBaseURL(tapestryApiBaseUrl),
+		WithExecution(execution),
+		WithBlockchain(Blockchain(blockchain)),
+		WithHTTPClient(httpClient),
+	)
+}
+
+// do sends req through the configured HTTPDoer. Every endpoint goes through
+// here so the transport is injected in exactly one place.
+func (c *TapestryClient) do(req *http.Request) (*http.Response, error) {
+	if c.httpClient == nil {
+		return http.DefaultClient.Do(req)
+	}
+	return c.httpClient.Do(req)
+}
+
+// doJSON sends a request for op with an optional JSON body and decodes a
+// successful response into out. A nil out discards the response body. Any
+// status outside 2xx is returned as an *APIError. uri starts with the client's
+// base URL, which is swapped for the one set with WithCallBaseURL, if any.
+func (c *TapestryClient) doJSON(ctx context.Context, op operation, uri string, in, out any) error {
+	if c.timeout > 0 {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+		}
 	}
+
+	if baseURL := c.baseURLFor(ctx); baseURL != c.tapestryApiBaseUrl {
+		if err := validateBaseURL(baseURL); err != nil {
+			return err
+		}
+		uri = baseURL + strings.TrimPrefix(uri, c.tapestryApiBaseUrl)
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	var execution Execution
+	if len(op.executions) > 0 {
+		execution = callOptionsFrom(ctx).execution
+	}
+
+	call := &Call{
+		Operation:    op.name,
+		ClientMethod: op.fn,
+		Method:       op.method,
+		URL:          u,
+		Header:       http.Header{},
+		Request:      in,
+		Response:     out,
+		Blockchain:   c.blockchainFor(ctx),
+		Execution:    execution,
+	}
+
+	return c.intercept(ctx, op, call)
+}
+
+// execute performs call once it has passed the interceptors and reports it to
+// the configured Metrics and logger.
+func (c *TapestryClient) execute(ctx context.Context, op operation, call *Call) error {
+	if c.metrics == nil && c.logger == nil {
+		return c.roundTrip(ctx, op, call, &callStats{})
+	}
+
+	stats := &callStats{}
+	start := time.Now()
+	err := c.roundTrip(ctx, op, call, stats)
+	duration := time.Since(start)
+
+	if c.metrics != nil {
+		c.metrics.ObserveCall(op.name, StatusClass(err), duration)
+	}
+	c.logCall(ctx, call, stats, duration, err)
+
+	return err
+}
+
+// roundTrip performs call over HTTP and records its status and payload sizes
+// in stats.
+func (c *TapestryClient) roundTrip(ctx context.Context, op operation, call *Call, stats *callStats) error {
+	var body io.Reader
+	if call.Request != nil {
+		jsonBody, err := json.Marshal(call.Request)
+		if err != nil {
+			return fmt.Errorf("error marshaling request: %w", err)
+		}
+		body = bytes.NewReader(jsonBody)
+		stats.requestBytes = len(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, call.Method, call.URL.String(), body)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	if call.Request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	for key, values := range call.Header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+
+	resp, err := c.send(op, req)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+	stats.status = resp.StatusCode
+	call.StatusCode = resp.StatusCode
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := newAPIError(resp)
+		stats.responseBytes = len(apiErr.Body)
+		return apiErr
+	}
+
+	if call.Response == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	respBody := &countingReader{r: resp.Body}
+	defer func() { stats.responseBytes = respBody.n }()
+	if err := json.NewDecoder(respBody).Decode(call.Response); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return nil
 }
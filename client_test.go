@@ -0,0 +1,97 @@
+package tapestry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type recordingDoer struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	client   *http.Client
+}
+
+func (d *recordingDoer) Do(req *http.Request) (*http.Response, error) {
+	d.mu.Lock()
+	d.requests = append(d.requests, req)
+	d.mu.Unlock()
+	return d.client.Do(req)
+}
+
+func TestTapestryClient_UsesInjectedHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"content":{"id":"c1"}}`))
+	}))
+	defer server.Close()
+
+	doer := &recordingDoer{client: server.Client()}
+	client := NewTapestryClientWithHTTPClient("key", server.URL, ExecutionFastUnconfirmed, "SOLANA", doer)
+	ctx := context.Background()
+	profile := Profile{ID: "p1", Username: "user"}
+
+	calls := []struct {
+		name string
+		call func() error
+	}{
+		{"FindOrCreateProfile", func() error {
+			_, err := client.FindOrCreateProfile(ctx, FindOrCreateProfileParameters{WalletAddress: testWallet, Username: "u"})
+			return err
+		}},
+		{"UpdateProfile", func() error { return client.UpdateProfile(ctx, "p1", UpdateProfileParameters{Username: "u"}) }},
+		{"GetProfileByID", func() error { _, err := client.GetProfileByID(ctx, "p1"); return err }},
+		{"GetFollowers", func() error { _, err := client.GetFollowers(ctx, "p1"); return err }},
+		{"GetFollowing", func() error { _, err := client.GetFollowing(ctx, "p1"); return err }},
+		{"GetFollowingWhoFollow", func() error { _, err := client.GetFollowingWhoFollow(ctx, "p1", "p2"); return err }},
+		{"GetSuggestedProfiles", func() error { _, err := client.GetSuggestedProfiles(ctx, testWallet, true); return err }},
+		{"FindOrCreateContent", func() error { _, err := client.FindOrCreateContent(ctx, "p1", "c1", nil); return err }},
+		{"UpdateContent", func() error { _, err := client.UpdateContent(ctx, "c1", nil); return err }},
+		{"DeleteContent", func() error { return client.DeleteContent(ctx, "c1") }},
+		{"GetContentByID", func() error { _, err := client.GetContentByID(ctx, "c1"); return err }},
+		{"GetContentsByBatchIDs", func() error { _, err := client.GetContentsByBatchIDs(ctx, []string{"c1"}); return err }},
+		{"GetContents", func() error { _, err := client.GetContents(ctx); return err }},
+		{"CreateComment", func() error {
+			_, err := client.CreateComment(ctx, CreateCommentOptions{ContentID: "c1", ProfileID: "p1", Text: "hi"})
+			return err
+		}},
+		{"GetComments", func() error { _, err := client.GetComments(ctx, GetCommentsOptions{ContentID: "c1"}); return err }},
+		{"GetCommentByID", func() error { _, err := client.GetCommentByID(ctx, "cm1", "p1"); return err }},
+		{"DeleteComment", func() error { return client.DeleteComment(ctx, "cm1") }},
+		{"UpdateComment", func() error { _, err := client.UpdateComment(ctx, "cm1", nil); return err }},
+		{"GetCommentReplies", func() error {
+			_, err := client.GetCommentReplies(ctx, "cm1", GetCommentRepliesOptions{})
+			return err
+		}},
+		{"CreateLike", func() error { return client.CreateLike(ctx, "c1", profile) }},
+		{"DeleteLike", func() error { return client.DeleteLike(ctx, "c1", profile) }},
+		{"AddFollower", func() error { return client.AddFollower(ctx, "p1", "p2") }},
+		{"RemoveFollower", func() error { return client.RemoveFollower(ctx, "p1", "p2") }},
+	}
+
+	for _, tt := range calls {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(doer.requests)
+			if err := tt.call(); err != nil {
+				t.Fatalf("%s() error = %v", tt.name, err)
+			}
+			if got := len(doer.requests) - before; got != 1 {
+				t.Errorf("%s() sent %d requests through the injected client, want 1", tt.name, got)
+			}
+		})
+	}
+}
+
+func TestTapestryClient_ZeroValueFallsBackToDefaultClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := TapestryClient{tapestryApiBaseUrl: server.URL}
+	if err := client.DeleteContent(context.Background(), "c1"); err != nil {
+		t.Fatalf("DeleteContent() error = %v", err)
+	}
+}
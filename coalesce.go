@@ -0,0 +1,104 @@
+package tapestry
+
+import (
+	"context"
+	"sync"
+)
+
+// WithCoalescing controls whether concurrent GetContentByID and
+// GetProfileByID calls for the same ID share one request. It is enabled by
+// default.
+func WithCoalescing(enabled bool) ClientOption {
+	return func(c *TapestryClient) {
+		if enabled {
+			c.flights = &flightGroup{}
+		} else {
+			c.flights = nil
+		}
+	}
+}
+
+// flightGroup deduplicates concurrent calls with the same key, like
+// golang.org/x/sync/singleflight, except that every caller can give up on its
+// own context and the shared call is only canceled once all of them have.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+type flight struct {
+	done    chan struct{}
+	val     any
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// coalesce runs fn once for all concurrent callers with the same key and
+// hands each of them the result. fn runs with a context that keeps the values
+// and deadline of the first caller's context but is only canceled when every
+// caller has left. A nil group calls fn directly.
+func coalesce[T any](ctx context.Context, g *flightGroup, key string, fn func(context.Context) (T, error)) (T, error) {
+	if g == nil {
+		return fn(ctx)
+	}
+
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+	f, ok := g.flights[key]
+	if !ok {
+		flightCtx, cancel := detach(ctx)
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		g.flights[key] = f
+
+		go func() {
+			val, err := fn(flightCtx)
+			cancel()
+
+			g.mu.Lock()
+			f.val, f.err = val, err
+			if g.flights[key] == f {
+				delete(g.flights, key)
+			}
+			g.mu.Unlock()
+			close(f.done)
+		}()
+	}
+	f.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		if f.err != nil {
+			var zero T
+			return zero, f.err
+		}
+		return f.val.(T), nil
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			// nobody is waiting for the result any more
+			f.cancel()
+			if g.flights[key] == f {
+				delete(g.flights, key)
+			}
+		}
+		g.mu.Unlock()
+
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// detach returns a context with the values and deadline of ctx that is not
+// canceled along with it.
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return context.WithCancel(detached)
+}
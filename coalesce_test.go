@@ -0,0 +1,195 @@
+package tapestry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingServer holds every request until release is closed.
+type blockingServer struct {
+	*recordingServer
+	arrived  chan struct{}
+	release  chan struct{}
+	canceled chan struct{}
+}
+
+func newBlockingServer(t *testing.T) *blockingServer {
+	s := &blockingServer{
+		arrived:  make(chan struct{}, 100),
+		release:  make(chan struct{}),
+		canceled: make(chan struct{}, 100),
+	}
+	s.recordingServer = newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		s.arrived <- struct{}{}
+		select {
+		case <-s.release:
+			_, _ = w.Write([]byte(`{"content":{"id":"c1"},"profile":{"id":"p1"}}`))
+		case <-r.Context().Done():
+			s.canceled <- struct{}{}
+		}
+	})
+	return s
+}
+
+// waitForWaiters blocks until n callers wait on the flight for key.
+func waitForWaiters(t *testing.T, g *flightGroup, key string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		f := g.flights[key]
+		joined := f != nil && f.waiters == n
+		g.mu.Unlock()
+		if joined {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%d callers did not join the flight for %s", n, key)
+}
+
+func TestCoalesce_SharesOneRequest(t *testing.T) {
+	server := newBlockingServer(t)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+
+	const callers = 20
+	var wg sync.WaitGroup
+	results := make([]*GetContentResponse, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = client.GetContentByID(context.Background(), "c1")
+		}(i)
+	}
+
+	waitForWaiters(t, client.flights, contentCacheKey("c1"), callers)
+	close(server.release)
+	wg.Wait()
+
+	if got := server.count(); got != 1 {
+		t.Errorf("server got %d requests, want 1", got)
+	}
+	for i := range results {
+		if errs[i] != nil || results[i].Content.ID != "c1" {
+			t.Fatalf("caller %d got %+v, %v", i, results[i], errs[i])
+		}
+	}
+	if results[0] == results[1] {
+		t.Errorf("callers share the same response value")
+	}
+}
+
+func TestCoalesce_CallerCancellation(t *testing.T) {
+	server := newBlockingServer(t)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+
+	impatient, cancel := context.WithCancel(context.Background())
+	impatientErr := make(chan error)
+	go func() {
+		_, err := client.GetProfileByID(impatient, "p1")
+		impatientErr <- err
+	}()
+	<-server.arrived
+
+	patientResult := make(chan *ProfileResponse)
+	go func() {
+		resp, _ := client.GetProfileByID(context.Background(), "p1")
+		patientResult <- resp
+	}()
+	waitForWaiters(t, client.flights, profileCacheKey("p1"), 2)
+
+	cancel()
+	if err := <-impatientErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled caller got %v, want context.Canceled", err)
+	}
+
+	close(server.release)
+	if resp := <-patientResult; resp == nil || resp.Profile.ID != "p1" {
+		t.Errorf("remaining caller got %+v", resp)
+	}
+	if got := server.count(); got != 1 {
+		t.Errorf("server got %d requests, want 1", got)
+	}
+}
+
+func TestCoalesce_CancelsWhenAllCallersLeave(t *testing.T) {
+	server := newBlockingServer(t)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_, _ = client.GetContentByID(ctx, "c1")
+		close(done)
+	}()
+	<-server.arrived
+	cancel()
+	<-done
+
+	select {
+	case <-server.canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shared request was not canceled after every caller left")
+	}
+}
+
+func TestCoalesce_Disabled(t *testing.T) {
+	server := newBlockingServer(t)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithCoalescing(false))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = client.GetContentByID(context.Background(), "c1")
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		<-server.arrived
+	}
+	close(server.release)
+	wg.Wait()
+
+	if got := server.count(); got != 3 {
+		t.Errorf("server got %d requests, want 3", got)
+	}
+}
+
+func TestCoalesce_KeepsCallerDeadline(t *testing.T) {
+	server := newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxRetryAfter: 10 * time.Second}))
+
+	calls := map[string]func(context.Context) error{
+		"GetContentByID": func(ctx context.Context) error {
+			_, err := client.GetContentByID(ctx, "c1")
+			return err
+		},
+		"GetProfileByID": func(ctx context.Context) error {
+			_, err := client.GetProfileByID(ctx, "p1")
+			return err
+		},
+	}
+	for name, call := range calls {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		start := time.Now()
+		err := call(ctx)
+		cancel()
+		if !errors.Is(err, ErrRateLimited) {
+			t.Errorf("%s() error = %v, want ErrRateLimited", name, err)
+		}
+		if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+			t.Errorf("%s() waited %v despite a Retry-After beyond the deadline", name, elapsed)
+		}
+	}
+}
@@ -2,13 +2,8 @@ package tapestry
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
 )
 
 type CommentProperty struct {
@@ -25,7 +20,7 @@ type CreateCommentOptions struct {
 
 type CreateCommentRequest struct {
 	CreateCommentOptions
-	Execution string `json:"execution"`
+	Execution string `json:"execution,omitempty"`
 }
 
 type CreateCommentResponse struct {
@@ -87,56 +82,41 @@ type GetCommentRepliesOptions struct {
 	PageSize            int
 }
 
-func (c *TapestryClient) CreateComment(ctx context.Context, options CreateCommentOptions) (*CreateCommentResponse, error) {
-	uri := fmt.Sprintf("%s/comments?apiKey=%s", c.tapestryApiBaseUrl, c.apiKey)
+func (c *TapestryClient) CreateComment(ctx context.Context, options CreateCommentOptions, opts ...CallOption) (*CreateCommentResponse, error) {
+	ctx, callOpts, err := c.prepareCall(ctx, opCreateComment, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	uri := c.endpoint(nil, "comments")
 	if options.Properties == nil {
 		options.Properties = []CommentProperty{}
 	}
 	reqData := CreateCommentRequest{
 		CreateCommentOptions: options,
-		Execution:            string(c.execution),
+		Execution:            string(callOpts.execution),
 	}
 	if options.CommentID != "" {
 		reqData.CommentID = options.CommentID
 	}
 
-	jsonBody, err := json.Marshal(reqData)
-	if err != nil {
-		return nil, fmt.Errorf("error marshaling request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, strings.NewReader(string(jsonBody)))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		// read the response body
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("error reading response body: %w", err)
-		}
-		return nil, fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
-	}
+	// the parent's comment or reply count changes
+	defer c.invalidate(ctx, contentCacheKey(options.ContentID), commentCacheKey(options.CommentID))
 
 	var commentResp CreateCommentResponse
-	if err := json.NewDecoder(resp.Body).Decode(&commentResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if err := c.doJSON(ctx, opCreateComment, uri, reqData, &commentResp); err != nil {
+		return nil, err
 	}
 
 	return &commentResp, nil
 }
 
+// GetComments returns an error matching ErrNotFound when the API does not know
+// the requested content or comment.
 func (c *TapestryClient) GetComments(ctx context.Context, options GetCommentsOptions) (*GetCommentsResponse, error) {
-	baseURL := fmt.Sprintf("%s/comments?apiKey=%s", c.tapestryApiBaseUrl, c.apiKey)
-
 	params := url.Values{}
 	if options.ContentID != "" {
 		params.Add("contentId", options.ContentID)
@@ -158,124 +138,80 @@ func (c *TapestryClient) GetComments(ctx context.Context, options GetCommentsOpt
 		params.Add("pageSize", strconv.Itoa(options.PageSize))
 	}
 
-	uri := baseURL
-	if len(params) > 0 {
-		uri += "&" + params.Encode()
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusNotFound {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	uri := c.endpoint(params, "comments")
 
 	var comments GetCommentsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&comments); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if err := c.doJSON(ctx, opGetComments, uri, nil, &comments); err != nil {
+		return nil, err
 	}
 
 	return &comments, nil
 }
 
 func (c *TapestryClient) GetCommentByID(ctx context.Context, commentID string, requestingProfileID string) (*GetCommentByIdResponse, error) {
-	uri := fmt.Sprintf("%s/comments/%s?apiKey=%s", c.tapestryApiBaseUrl, commentID, c.apiKey)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+	var commentResp GetCommentByIdResponse
+	if c.cacheGet(ctx, commentCacheKey(commentID), &commentResp) {
+		return &commentResp, nil
 	}
-
-	resp, err := http.DefaultClient.Do(req)
+	fill := c.startFill(ctx, commentCacheKey(commentID))
+	defer fill.done()
+	commentResp, err := c.fetchComment(ctx, commentID)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	var commentResp GetCommentByIdResponse
-	if err := json.NewDecoder(resp.Body).Decode(&commentResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+		return nil, err
 	}
+	fill.set(commentResp)
 
 	return &commentResp, nil
 }
 
-func (c *TapestryClient) DeleteComment(ctx context.Context, commentID string) error {
-	uri := fmt.Sprintf("%s/comments/%s?apiKey=%s", c.tapestryApiBaseUrl, commentID, c.apiKey)
+// fetchComment reads the comment from the API, bypassing the cache.
+func (c *TapestryClient) fetchComment(ctx context.Context, commentID string) (GetCommentByIdResponse, error) {
+	uri := c.endpoint(nil, "comments", commentID)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, uri, nil)
-	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
-	}
+	var commentResp GetCommentByIdResponse
+	err := c.doJSON(ctx, opGetComment, uri, nil, &commentResp)
+	return commentResp, err
+}
 
-	resp, err := http.DefaultClient.Do(req)
+func (c *TapestryClient) DeleteComment(ctx context.Context, commentID string, opts ...CallOption) error {
+	ctx, _, err := c.prepareCall(ctx, opDeleteComment, opts)
 	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	uri := c.endpoint(nil, "comments", commentID)
 
-	return nil
-}
-
-func (c *TapestryClient) UpdateComment(ctx context.Context, commentID string, properties []CommentProperty) (*UpdateCommentResponse, error) {
-	uri := fmt.Sprintf("%s/comments/%s?apiKey=%s", c.tapestryApiBaseUrl, commentID, c.apiKey)
+	contentID := c.commentContentID(ctx, commentID)
+	defer c.invalidateComment(ctx, commentID, contentID)
 
-	reqData := UpdateCommentRequest{
-		Properties: properties,
-	}
+	return c.doJSON(ctx, opDeleteComment, uri, nil, nil)
+}
 
-	jsonBody, err := json.Marshal(reqData)
+func (c *TapestryClient) UpdateComment(ctx context.Context, commentID string, properties []CommentProperty, opts ...CallOption) (*UpdateCommentResponse, error) {
+	ctx, _, err := c.prepareCall(ctx, opUpdateComment, opts)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling request: %w", err)
+		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uri, strings.NewReader(string(jsonBody)))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
+	uri := c.endpoint(nil, "comments", commentID)
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+	reqData := UpdateCommentRequest{
+		Properties: properties,
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
-	}
+	defer c.invalidate(ctx, commentCacheKey(commentID))
 
 	var commentResp UpdateCommentResponse
-	if err := json.NewDecoder(resp.Body).Decode(&commentResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if err := c.doJSON(ctx, opUpdateComment, uri, reqData, &commentResp); err != nil {
+		return nil, err
 	}
 
 	return &commentResp, nil
 }
 
+// GetCommentReplies returns an error matching ErrNotFound when the parent
+// comment does not exist.
 func (c *TapestryClient) GetCommentReplies(ctx context.Context, commentID string, options GetCommentRepliesOptions) (*GetCommentsResponse, error) {
-	baseURL := fmt.Sprintf("%s/comments/%s/replies?apiKey=%s", c.tapestryApiBaseUrl, commentID, c.apiKey)
-
 	params := url.Values{}
 	if options.RequestingProfileID != "" {
 		params.Add("requestingProfileId", options.RequestingProfileID)
@@ -287,34 +223,11 @@ func (c *TapestryClient) GetCommentReplies(ctx context.Context, commentID string
 		params.Add("pageSize", strconv.Itoa(options.PageSize))
 	}
 
-	uri := baseURL
-	if len(params) > 0 {
-		uri += "&" + params.Encode()
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusNotFound {
-			return nil, nil
-		}
-		// Read error response body
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
-	}
+	uri := c.endpoint(params, "comments", commentID, "replies")
 
 	var replies GetCommentsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&replies); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if err := c.doJSON(ctx, opGetCommentReplies, uri, nil, &replies); err != nil {
+		return nil, err
 	}
 
 	return &replies, nil
@@ -2,10 +2,9 @@ package tapestry
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
-	"strings"
+	"net/url"
 )
 
 type ContentProperty struct {
@@ -17,10 +16,12 @@ type FindOrCreateContentRequest struct {
 	ProfileID  string            `json:"profileId"`
 	ID         string            `json:"id,omitempty"`
 	Properties []ContentProperty `json:"properties"`
+	Execution  string            `json:"execution,omitempty"`
 }
 
 type UpdateContentRequest struct {
 	Properties []ContentProperty `json:"properties"`
+	Execution  string            `json:"execution,omitempty"`
 }
 
 type Content struct {
@@ -69,10 +70,13 @@ type BatchResponseContentListItem struct {
 
 type GetContentsByBatchIDsResponse struct {
 	Successful []BatchResponseContentListItem `json:"successful"`
-	Failed     []struct {
-		ID    string `json:"id"`
-		Error string `json:"error"`
-	} `json:"failed"`
+	Failed     []BatchResponseFailedItem      `json:"failed"`
+}
+
+// BatchResponseFailedItem is a content ID a batch read could not return.
+type BatchResponseFailedItem struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
 }
 
 type SocialCounts struct {
@@ -80,194 +84,215 @@ type SocialCounts struct {
 	CommentCount int `json:"commentCount"`
 }
 
-func (c *TapestryClient) FindOrCreateContent(ctx context.Context, profileId, id string, properties []ContentProperty) (*CreateOrUpdateContentResponse, error) {
-	url := fmt.Sprintf("%s/contents/findOrCreate?apiKey=%s", c.tapestryApiBaseUrl, c.apiKey)
+func (c *TapestryClient) FindOrCreateContent(ctx context.Context, profileId, id string, properties []ContentProperty, opts ...CallOption) (*CreateOrUpdateContentResponse, error) {
+	ctx, callOpts, err := c.prepareCall(ctx, opFindOrCreateContent, opts)
+	if err != nil {
+		return nil, err
+	}
 
-	jsonBody, err := json.Marshal(FindOrCreateContentRequest{
+	reqData := FindOrCreateContentRequest{
 		ProfileID:  profileId,
 		ID:         id,
 		Properties: properties,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("error marshaling request: %w", err)
+		Execution:  string(callOpts.execution),
 	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(string(jsonBody)))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+	if err := reqData.Validate(); err != nil {
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
-	}
-	defer resp.Body.Close()
+	uri := c.endpoint(nil, "contents", "findOrCreate")
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	defer c.invalidate(ctx, contentCacheKey(id))
 
 	var contentResp CreateOrUpdateContentResponse
-	if err := json.NewDecoder(resp.Body).Decode(&contentResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if err := c.doJSON(ctx, opFindOrCreateContent, uri, reqData, &contentResp); err != nil {
+		return nil, err
 	}
 
 	return &contentResp, nil
 }
 
-func (c *TapestryClient) UpdateContent(ctx context.Context, contentId string, properties []ContentProperty) (*CreateOrUpdateContentResponse, error) {
-	url := fmt.Sprintf("%s/contents/%s?apiKey=%s", c.tapestryApiBaseUrl, contentId, c.apiKey)
-
-	jsonBody, err := json.Marshal(UpdateContentRequest{
-		Properties: properties,
-	})
+func (c *TapestryClient) UpdateContent(ctx context.Context, contentId string, properties []ContentProperty, opts ...CallOption) (*CreateOrUpdateContentResponse, error) {
+	ctx, callOpts, err := c.prepareCall(ctx, opUpdateContent, opts)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling request: %w", err)
+		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, strings.NewReader(string(jsonBody)))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
+	uri := c.endpoint(nil, "contents", contentId)
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	defer c.invalidate(ctx, contentCacheKey(contentId))
 
 	var contentResp CreateOrUpdateContentResponse
-	if err := json.NewDecoder(resp.Body).Decode(&contentResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if err := c.doJSON(ctx, opUpdateContent, uri, UpdateContentRequest{
+		Properties: properties,
+		Execution:  string(callOpts.execution),
+	}, &contentResp); err != nil {
+		return nil, err
 	}
 
 	return &contentResp, nil
 }
 
-func (c *TapestryClient) DeleteContent(ctx context.Context, contentId string) error {
-	url := fmt.Sprintf("%s/contents/%s?apiKey=%s", c.tapestryApiBaseUrl, contentId, c.apiKey)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+func (c *TapestryClient) DeleteContent(ctx context.Context, contentId string, opts ...CallOption) error {
+	ctx, _, err := c.prepareCall(ctx, opDeleteContent, opts)
 	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
+		return err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
-	}
-	defer resp.Body.Close()
+	uri := c.endpoint(nil, "contents", contentId)
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	defer c.invalidate(ctx, contentCacheKey(contentId))
 
-	return nil
+	return c.doJSON(ctx, opDeleteContent, uri, nil, nil)
 }
 
+// GetContentByID returns an error matching ErrNotFound when the content does
+// not exist. The API answers 401 for unknown content, so such an error also
+// matches ErrUnauthorized. With WithContentLoader, the lookup is batched with
+// concurrent ones.
 func (c *TapestryClient) GetContentByID(ctx context.Context, contentId string) (*GetContentResponse, error) {
-	url := fmt.Sprintf("%s/contents/%s?apiKey=%s", c.tapestryApiBaseUrl, contentId, c.apiKey)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+	var contentResp GetContentResponse
+	if c.cacheGet(ctx, contentCacheKey(contentId), &contentResp) {
+		return &contentResp, nil
 	}
-	defer resp.Body.Close()
 
-	// unauthorized may also mean not found
-	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnauthorized {
-		return nil, nil
-	}
+	contentResp, err := coalesce(ctx, c.flights, c.scopedKey(ctx, contentCacheKey(contentId)), func(ctx context.Context) (GetContentResponse, error) {
+		if c.contentLoader != nil {
+			loaded, err := c.contentLoader.load(ctx, c, contentId)
+			if err != nil {
+				return GetContentResponse{}, err
+			}
+			return *loaded, nil
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+		fill := c.startFill(ctx, contentCacheKey(contentId))
+		defer fill.done()
 
-	var contentResp GetContentResponse
-	if err := json.NewDecoder(resp.Body).Decode(&contentResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
-	}
+		contentResp, err := c.fetchContent(ctx, contentId)
+		if err != nil {
+			return contentResp, err
+		}
+		fill.set(contentResp)
 
-	// not found
-	if contentResp.Content.ID == "" {
-		return nil, nil
+		return contentResp, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &contentResp, nil
 }
 
-func (c *TapestryClient) GetContentsByBatchIDs(ctx context.Context, batchIDs []string) (*GetContentsByBatchIDsResponse, error) {
-	url := fmt.Sprintf("%s/contents/batch/read?apiKey=%s", c.tapestryApiBaseUrl, c.apiKey)
-
-	jsonBody, err := json.Marshal(batchIDs)
-	if err != nil {
-		return nil, fmt.Errorf("error marshaling request: %w", err)
-	}
+// fetchContent reads the content from the API, bypassing the cache, the
+// coalescing of concurrent reads and the ContentLoader.
+func (c *TapestryClient) fetchContent(ctx context.Context, contentId string) (GetContentResponse, error) {
+	uri := c.endpoint(nil, "contents", contentId)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(string(jsonBody)))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+	var contentResp GetContentResponse
+	if err := c.doJSON(ctx, opGetContent, uri, nil, &contentResp); err != nil {
+		// the API answers 401 for unknown content too
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+			apiErr.MaybeNotFound = true
+		}
+		return contentResp, err
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
-	}
-	defer resp.Body.Close()
+	// the API answers 200 with an empty body for unknown content
+	if contentResp.Content.ID == "" {
+		return contentResp, &APIError{
+			StatusCode: http.StatusNotFound,
+			Method:     http.MethodGet,
+			Endpoint:   endpointPath(uri),
+			Message:    "content not found",
+		}
+	}
+	return contentResp, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+// errNotReturned is the Error of the failed items added for IDs the API
+// answered neither way.
+const errNotReturned = "not returned by the API"
 
-	var batchResp GetContentsByBatchIDsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&batchResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
-	}
+// GetContentsByBatchIDs reads several contents at once. Repeated IDs are read
+// once, and Successful and Failed follow the order of batchIDs. Every ID the
+// API leaves out of both is added to Failed, and returned contents whose ID
+// matches none of batchIDs are appended to Successful. Long lists are split
+// into chunks read concurrently, see WithBatchReads. With WithCache, only the
+// IDs missing from the cache are requested.
+func (c *TapestryClient) GetContentsByBatchIDs(ctx context.Context, batchIDs []string) (*GetContentsByBatchIDsResponse, error) {
+	ids := uniqueIDs(batchIDs)
+
+	found := make(map[string]BatchResponseContentListItem, len(ids))
+	failed := make(map[string]BatchResponseFailedItem)
+	var missing []string
+	var unrequested []BatchResponseContentListItem
+	for _, id := range ids {
+		var item BatchResponseContentListItem
+		if c.cacheGet(ctx, contentCacheKey(id), &item) {
+			found[id] = item
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) > 0 {
+		fills := make(map[string]*cacheFill, len(missing))
+		for _, id := range missing {
+			fill := c.startFill(ctx, contentCacheKey(id))
+			defer fill.done()
+			fills[id] = fill
+		}
+
+		responses, err := c.readContentBatches(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, resp := range responses {
+			for _, item := range resp.Successful {
+				fill, ok := fills[item.Content.ID]
+				if !ok {
+					unrequested = append(unrequested, item)
+					continue
+				}
+				found[item.Content.ID] = item
+				fill.set(item)
+			}
+			for _, item := range resp.Failed {
+				failed[item.ID] = item
+			}
+		}
+	}
+
+	batchResp := GetContentsByBatchIDsResponse{
+		Successful: make([]BatchResponseContentListItem, 0, len(found)),
+	}
+	for _, id := range ids {
+		if item, ok := found[id]; ok {
+			batchResp.Successful = append(batchResp.Successful, item)
+		} else if item, ok := failed[id]; ok {
+			batchResp.Failed = append(batchResp.Failed, item)
+		} else {
+			batchResp.Failed = append(batchResp.Failed, BatchResponseFailedItem{ID: id, Error: errNotReturned})
+		}
+	}
+	batchResp.Successful = append(batchResp.Successful, unrequested...)
 
 	return &batchResp, nil
 }
 
 func (c *TapestryClient) GetContents(ctx context.Context, opts ...GetContentsOption) (*GetContentsResponse, error) {
-	params := &getContentsParams{
-		apiKey: c.apiKey,
-	}
+	params := &getContentsParams{}
 
 	for _, opt := range opts {
 		opt(params)
 	}
 
-	url := fmt.Sprintf("%s/contents/?%s", c.tapestryApiBaseUrl, params.encode())
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	uri := c.endpoint(params.values(), "contents", "")
 
 	var contentsResp GetContentsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&contentsResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if err := c.doJSON(ctx, opGetContents, uri, nil, &contentsResp); err != nil {
+		return nil, err
 	}
 
 	return &contentsResp, nil
@@ -281,7 +306,6 @@ const (
 )
 
 type getContentsParams struct {
-	apiKey              string
 	orderByField        string
 	orderByDirection    GetContentsSortDirection
 	page                string
@@ -290,30 +314,27 @@ type getContentsParams struct {
 	requestingProfileID string
 }
 
-func (p *getContentsParams) encode() string {
-	values := make([]string, 0)
-	values = append(values, fmt.Sprintf("apiKey=%s", p.apiKey))
-
+func (p *getContentsParams) values() url.Values {
+	values := url.Values{}
 	if p.orderByField != "" {
-		values = append(values, fmt.Sprintf("orderByField=%s", p.orderByField))
+		values.Set("orderByField", p.orderByField)
 	}
 	if p.orderByDirection != "" {
-		values = append(values, fmt.Sprintf("orderByDirection=%s", p.orderByDirection))
+		values.Set("orderByDirection", string(p.orderByDirection))
 	}
 	if p.page != "" {
-		values = append(values, fmt.Sprintf("page=%s", p.page))
+		values.Set("page", p.page)
 	}
 	if p.pageSize != "" {
-		values = append(values, fmt.Sprintf("pageSize=%s", p.pageSize))
+		values.Set("pageSize", p.pageSize)
 	}
 	if p.profileID != "" {
-		values = append(values, fmt.Sprintf("profileId=%s", p.profileID))
+		values.Set("profileId", p.profileID)
 	}
 	if p.requestingProfileID != "" {
-		values = append(values, fmt.Sprintf("requestingProfileId=%s", p.requestingProfileID))
+		values.Set("requestingProfileId", p.requestingProfileID)
 	}
-
-	return strings.Join(values, "&")
+	return values
 }
 
 // Option pattern for configurable parameters
@@ -0,0 +1,134 @@
+package tapestry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Sentinel errors matched by *APIError through errors.Is.
+var (
+	ErrNotFound     = errors.New("tapestry: not found")
+	ErrUnauthorized = errors.New("tapestry: unauthorized")
+	ErrRateLimited  = errors.New("tapestry: rate limited")
+	ErrConflict     = errors.New("tapestry: conflict")
+	ErrValidation   = errors.New("tapestry: validation failed")
+)
+
+// maxErrorBodySize caps how much of an error response body is kept.
+const maxErrorBodySize = 64 << 10
+
+// requestIDHeaders are checked in order for a server-assigned request ID.
+var requestIDHeaders = []string{"X-Request-Id", "X-Amzn-Requestid", "X-Correlation-Id"}
+
+// APIError is returned when the Tapestry API answers with a non-200 status.
+type APIError struct {
+	StatusCode int
+	Method     string
+	// Endpoint is the escaped request path, without the query string.
+	Endpoint string
+	// Message is the error message decoded from the response body, or the raw
+	// body when it is not JSON.
+	Message   string
+	RequestID string
+	Body      []byte
+	// MaybeNotFound is set on 401 answers from endpoints that also use them
+	// for resources they do not know, so the error matches ErrNotFound as
+	// well as ErrUnauthorized.
+	MaybeNotFound bool
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "unexpected status code: %d (%s %s)", e.StatusCode, e.Method, e.Endpoint)
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " [request id %s]", e.RequestID)
+	}
+	return b.String()
+}
+
+// Is reports whether the status code of e falls into the class of target,
+// so callers can write errors.Is(err, tapestry.ErrNotFound).
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || e.MaybeNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	}
+	return false
+}
+
+// newAPIError builds an *APIError from a non-200 response. It consumes the
+// response body but does not close it.
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		if resp.Request.URL != nil {
+			apiErr.Endpoint = resp.Request.URL.EscapedPath()
+		}
+	}
+	for _, header := range requestIDHeaders {
+		if id := resp.Header.Get(header); id != "" {
+			apiErr.RequestID = id
+			break
+		}
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	apiErr.Body = body
+	apiErr.Message = decodeErrorMessage(body)
+
+	return apiErr
+}
+
+// decodeErrorMessage extracts a human readable message from an error body.
+// The API answers with either {"error": "..."} or {"message": "..." | [...]}.
+func decodeErrorMessage(body []byte) string {
+	var payload struct {
+		Error   json.RawMessage `json:"error"`
+		Message json.RawMessage `json:"message"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return strings.TrimSpace(string(body))
+	}
+
+	for _, raw := range []json.RawMessage{payload.Message, payload.Error} {
+		if len(raw) == 0 {
+			continue
+		}
+		var text string
+		if err := json.Unmarshal(raw, &text); err == nil && text != "" {
+			return text
+		}
+		var list []string
+		if err := json.Unmarshal(raw, &list); err == nil && len(list) > 0 {
+			return strings.Join(list, "; ")
+		}
+	}
+
+	return strings.TrimSpace(string(body))
+}
+
+// endpointPath returns the path of uri for use in an APIError.
+func endpointPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	return u.EscapedPath()
+}
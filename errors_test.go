@@ -0,0 +1,106 @@
+package tapestry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusConflict, ErrConflict},
+		{http.StatusBadRequest, ErrValidation},
+		{http.StatusUnprocessableEntity, ErrValidation},
+	}
+	sentinels := []error{ErrNotFound, ErrUnauthorized, ErrRateLimited, ErrConflict, ErrValidation}
+
+	for _, tt := range tests {
+		err := &APIError{StatusCode: tt.status}
+		for _, sentinel := range sentinels {
+			if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+				t.Errorf("errors.Is(%d, %v) = %v", tt.status, sentinel, got)
+			}
+		}
+	}
+
+	err := &APIError{StatusCode: http.StatusUnauthorized, MaybeNotFound: true}
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("a 401 that may mean not found should match ErrNotFound and ErrUnauthorized")
+	}
+}
+
+func TestDecodeErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"error field", `{"error":"profile not found"}`, "profile not found"},
+		{"message field", `{"message":"bad username","statusCode":400}`, "bad username"},
+		{"message list", `{"message":["username too short","bio too long"],"error":"Bad Request"}`, "username too short; bio too long"},
+		{"plain text", "Internal Server Error\n", "Internal Server Error"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeErrorMessage([]byte(tt.body)); got != tt.want {
+				t.Errorf("decodeErrorMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTapestryClient_ReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/profiles/"):
+			w.Header().Set("X-Request-Id", "req-123")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"Profile not found"}`))
+		case r.URL.Path == "/contents/missing":
+			_, _ = w.Write([]byte(`{}`))
+		case r.URL.Path == "/contents/private":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	client := NewTapestryClientWithHTTPClient("key", server.URL, ExecutionFastUnconfirmed, "SOLANA", server.Client())
+	ctx := context.Background()
+
+	profile, err := client.GetProfileByID(ctx, "p1")
+	if profile != nil || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetProfileByID() = %v, %v; want ErrNotFound", profile, err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetProfileByID() error is %T, want *APIError", err)
+	}
+	if apiErr.Method != http.MethodGet || apiErr.Endpoint != "/profiles/p1" ||
+		apiErr.Message != "Profile not found" || apiErr.RequestID != "req-123" {
+		t.Errorf("unexpected APIError: %+v", apiErr)
+	}
+
+	if _, err := client.GetContentByID(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetContentByID(missing) error = %v, want ErrNotFound", err)
+	}
+	if _, err := client.GetContentByID(ctx, "private"); !errors.Is(err, ErrUnauthorized) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetContentByID(private) error = %v, want ErrUnauthorized and ErrNotFound", err)
+	}
+	if err := client.AddFollower(ctx, "a", "b"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("AddFollower() error = %v, want ErrRateLimited", err)
+	}
+}
@@ -0,0 +1,103 @@
+package tapestry
+
+import (
+	"context"
+	"fmt"
+)
+
+// writeExecutions are the execution modes accepted by writes that take one.
+var writeExecutions = []Execution{ExecutionFastUnconfirmed, ExecutionQuickSignature, ExecutionConfirmedParsed}
+
+// CallOption overrides a client setting for a single call. Writes take call
+// options as arguments; ContextWithCallOptions applies them to every call made
+// with a context, reads included.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	execution  Execution
+	apiKey     string
+	baseURL    string
+	blockchain Blockchain
+}
+
+// WithCallExecution sets the execution mode of a single write, overriding the
+// client default set with WithExecution.
+//
+// FindOrCreateProfile, UpdateProfile, FindOrCreateContent, UpdateContent,
+// CreateComment, CreateLike, DeleteLike and AddFollower accept every
+// Execution. DeleteContent, UpdateComment, DeleteComment and RemoveFollower
+// take no execution mode and reject this option with an
+// *UnsupportedExecutionError. Set through ContextWithCallOptions, it applies
+// only to the writes that accept it.
+func WithCallExecution(execution Execution) CallOption {
+	return func(o *callOptions) {
+		o.execution = execution
+	}
+}
+
+// UnsupportedExecutionError reports an execution mode the endpoint of a write
+// does not accept. It matches ErrValidation.
+type UnsupportedExecutionError struct {
+	// Operation is the client method, for example "DeleteContent".
+	Operation string
+	Execution Execution
+}
+
+func (e *UnsupportedExecutionError) Error() string {
+	return fmt.Sprintf("tapestry: %s does not support execution mode %q", e.Operation, e.Execution)
+}
+
+func (e *UnsupportedExecutionError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+type callOptionsKey struct{}
+
+// ContextWithCallOptions returns a copy of ctx that applies opts to every
+// call made with it, on top of any options ctx already carries. Options
+// passed to a write take precedence over the ones in its context.
+func ContextWithCallOptions(ctx context.Context, opts ...CallOption) context.Context {
+	o := callOptionsFrom(ctx)
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return context.WithValue(ctx, callOptionsKey{}, o)
+}
+
+func callOptionsFrom(ctx context.Context) callOptions {
+	o, _ := ctx.Value(callOptionsKey{}).(callOptions)
+	return o
+}
+
+// prepareCall applies opts for a call of op on top of the options in ctx and
+// checks the resulting execution mode against the ones op accepts. Endpoints
+// that take an execution mode fall back to the client default. The returned
+// context carries the options through the request pipeline.
+func (c *TapestryClient) prepareCall(ctx context.Context, op operation, opts []CallOption) (context.Context, callOptions, error) {
+	o := callOptionsFrom(ctx)
+	if len(op.executions) == 0 {
+		// a context-wide execution mode only applies where it can
+		o.execution = ""
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.execution == "" && len(op.executions) > 0 {
+		o.execution = c.execution
+	}
+	if o.execution != "" && !op.supportsExecution(o.execution) {
+		return ctx, o, &UnsupportedExecutionError{Operation: op.fn, Execution: o.execution}
+	}
+
+	return context.WithValue(ctx, callOptionsKey{}, o), o, nil
+}
+
+func (op operation) supportsExecution(execution Execution) bool {
+	for _, supported := range op.executions {
+		if supported == execution {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package tapestry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestCallExecution(t *testing.T) {
+	server := newRecordingServer(t, nil)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithExecution(ExecutionQuickSignature))
+	ctx := context.Background()
+	profile := Profile{ID: "p1", Username: "alice"}
+
+	writes := []struct {
+		name      string
+		supported bool
+		call      func(opts ...CallOption) error
+	}{
+		{"FindOrCreateProfile", true, func(opts ...CallOption) error {
+			_, err := client.FindOrCreateProfile(ctx, FindOrCreateProfileParameters{WalletAddress: testWallet, Username: "alice"}, opts...)
+			return err
+		}},
+		{"UpdateProfile", true, func(opts ...CallOption) error {
+			return client.UpdateProfile(ctx, "p1", UpdateProfileParameters{Username: "alice"}, opts...)
+		}},
+		{"FindOrCreateContent", true, func(opts ...CallOption) error {
+			_, err := client.FindOrCreateContent(ctx, "p1", "c1", nil, opts...)
+			return err
+		}},
+		{"UpdateContent", true, func(opts ...CallOption) error {
+			_, err := client.UpdateContent(ctx, "c1", nil, opts...)
+			return err
+		}},
+		{"CreateComment", true, func(opts ...CallOption) error {
+			_, err := client.CreateComment(ctx, CreateCommentOptions{ContentID: "c1", ProfileID: "p1", Text: "hi"}, opts...)
+			return err
+		}},
+		{"CreateLike", true, func(opts ...CallOption) error { return client.CreateLike(ctx, "c1", profile, opts...) }},
+		{"DeleteLike", true, func(opts ...CallOption) error { return client.DeleteLike(ctx, "c1", profile, opts...) }},
+		{"AddFollower", true, func(opts ...CallOption) error { return client.AddFollower(ctx, "p1", "p2", opts...) }},
+		{"DeleteContent", false, func(opts ...CallOption) error { return client.DeleteContent(ctx, "c1", opts...) }},
+		{"UpdateComment", false, func(opts ...CallOption) error {
+			_, err := client.UpdateComment(ctx, "m1", nil, opts...)
+			return err
+		}},
+		{"DeleteComment", false, func(opts ...CallOption) error { return client.DeleteComment(ctx, "m1", opts...) }},
+		{"RemoveFollower", false, func(opts ...CallOption) error { return client.RemoveFollower(ctx, "p1", "p2", opts...) }},
+	}
+
+	lastExecution := func() any {
+		// deletes send no body
+		var body map[string]any
+		_ = json.Unmarshal(server.last().Body, &body)
+		return body["execution"]
+	}
+
+	for _, w := range writes {
+		t.Run(w.name, func(t *testing.T) {
+			if err := w.call(); err != nil {
+				t.Fatalf("default call error = %v", err)
+			}
+			got := lastExecution()
+			if w.supported && got != string(ExecutionQuickSignature) {
+				t.Errorf("default execution = %v, want the client default", got)
+			}
+			if !w.supported && got != nil {
+				t.Errorf("sent execution %v to an endpoint that takes none", got)
+			}
+
+			before := server.count()
+			err := w.call(WithCallExecution(ExecutionConfirmedParsed))
+			if !w.supported {
+				var unsupported *UnsupportedExecutionError
+				if !errors.As(err, &unsupported) || !errors.Is(err, ErrValidation) || unsupported.Operation != w.name {
+					t.Errorf("error = %v, want an UnsupportedExecutionError", err)
+				}
+				if server.count() != before {
+					t.Errorf("rejected call was sent")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("call with execution error = %v", err)
+			}
+			if got := lastExecution(); got != string(ExecutionConfirmedParsed) {
+				t.Errorf("execution = %v, want the per-call override", got)
+			}
+
+			if err := w.call(WithCallExecution("FAST")); !errors.Is(err, ErrValidation) {
+				t.Errorf("unknown execution error = %v, want ErrValidation", err)
+			}
+		})
+	}
+}
+
+func TestCallExecution_ReachesInterceptors(t *testing.T) {
+	var got Execution
+	client, _ := New("key", WithHTTPClient(failingDoer{}), WithInterceptors(func(ctx context.Context, call *Call, next Invoker) error {
+		got = call.Execution
+		return nil
+	}))
+
+	if err := client.CreateLike(context.Background(), "c1", Profile{ID: "p1"}, WithCallExecution(ExecutionConfirmedParsed)); err != nil {
+		t.Fatalf("CreateLike() error = %v", err)
+	}
+	if got != ExecutionConfirmedParsed {
+		t.Errorf("interceptor saw execution %q, want %q", got, ExecutionConfirmedParsed)
+	}
+}
@@ -2,73 +2,49 @@ package tapestry
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"strings"
 )
 
 type FollowRequest struct {
-	StartID string `json:"startId"`
-	EndID   string `json:"endId"`
+	StartID   string `json:"startId"`
+	EndID     string `json:"endId"`
+	Execution string `json:"execution,omitempty"`
 }
 
-func (c *TapestryClient) AddFollower(ctx context.Context, startID, endID string) error {
-	url := fmt.Sprintf("%s/followers/add?apiKey=%s", c.tapestryApiBaseUrl, c.apiKey)
-
-	jsonBody, err := json.Marshal(FollowRequest{
-		StartID: startID,
-		EndID:   endID,
-	})
+func (c *TapestryClient) AddFollower(ctx context.Context, startID, endID string, opts ...CallOption) error {
+	ctx, callOpts, err := c.prepareCall(ctx, opAddFollower, opts)
 	if err != nil {
-		return fmt.Errorf("error marshaling request: %w", err)
+		return err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(string(jsonBody)))
-	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
+	reqData := FollowRequest{
+		StartID:   startID,
+		EndID:     endID,
+		Execution: string(callOpts.execution),
 	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
+	if err := reqData.Validate(); err != nil {
+		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	uri := c.endpoint(nil, "followers", "add")
 
-	return nil
+	return c.doJSON(ctx, opAddFollower, uri, reqData, nil)
 }
 
-func (c *TapestryClient) RemoveFollower(ctx context.Context, startID, endID string) error {
-	url := fmt.Sprintf("%s/followers/remove?apiKey=%s", c.tapestryApiBaseUrl, c.apiKey)
-
-	jsonBody, err := json.Marshal(FollowRequest{
-		StartID: startID,
-		EndID:   endID,
-	})
+func (c *TapestryClient) RemoveFollower(ctx context.Context, startID, endID string, opts ...CallOption) error {
+	ctx, _, err := c.prepareCall(ctx, opRemoveFollower, opts)
 	if err != nil {
-		return fmt.Errorf("error marshaling request: %w", err)
+		return err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(string(jsonBody)))
-	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
+	reqData := FollowRequest{
+		StartID: startID,
+		EndID:   endID,
 	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
+	if err := reqData.Validate(); err != nil {
+		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	uri := c.endpoint(nil, "followers", "remove")
 
-	return nil
+	return c.doJSON(ctx, opRemoveFollower, uri, reqData, nil)
 }
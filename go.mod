@@ -1,3 +1,3 @@
 module github.com/Access-Labs-Inc/tapestry-go
 
-go 1.18
+go 1.21
@@ -0,0 +1,40 @@
+package tapestry
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Hooks are callbacks invoked around every HTTP attempt, including retries.
+// The operation argument names the SDK call, for example
+// "contents.findOrCreate". Any hook may be nil.
+type Hooks struct {
+	// OnRequest is called before an attempt is sent. It may add headers to
+	// req. Every attempt gets a fresh req, and the API key is attached to a
+	// copy after OnRequest returns, so no hook ever sees it.
+	OnRequest func(ctx context.Context, operation string, req *http.Request)
+	// OnResponse is called after an attempt completes, with either a response
+	// or a transport error. The response body must not be consumed.
+	OnResponse func(ctx context.Context, operation string, req *http.Request, resp *http.Response, err error, duration time.Duration)
+	// OnRetry is called before the client waits to retry an attempt.
+	OnRetry func(ctx context.Context, operation string, attempt int, wait time.Duration)
+}
+
+func (h Hooks) request(ctx context.Context, op operation, req *http.Request) {
+	if h.OnRequest != nil {
+		h.OnRequest(ctx, op.name, req)
+	}
+}
+
+func (h Hooks) response(ctx context.Context, op operation, req *http.Request, resp *http.Response, err error, duration time.Duration) {
+	if h.OnResponse != nil {
+		h.OnResponse(ctx, op.name, req, resp, err, duration)
+	}
+}
+
+func (h Hooks) retry(ctx context.Context, op operation, attempt int, wait time.Duration) {
+	if h.OnRetry != nil {
+		h.OnRetry(ctx, op.name, attempt, wait)
+	}
+}
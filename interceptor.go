@@ -0,0 +1,71 @@
+package tapestry
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Call describes one SDK operation on its way through the interceptor chain.
+// Interceptors may change any field before passing the call on.
+type Call struct {
+	// Operation names the SDK call, for example "contents.findOrCreate".
+	Operation string
+	// ClientMethod is the TapestryClient method making the call, for example
+	// "FindOrCreateContent".
+	ClientMethod string
+	Method       string
+	// URL is the endpoint without the API key.
+	URL *url.URL
+	// Header holds extra headers sent with every attempt of the call.
+	Header http.Header
+	// Request is the value encoded as the JSON request body, or nil.
+	Request any
+	// Response is the pointer the JSON response is decoded into, or nil when
+	// the response body is discarded. An interceptor that short-circuits the
+	// call may fill it in itself.
+	Response any
+	// Execution is the execution mode sent with a write, empty for reads and
+	// for writes that take none.
+	Execution  Execution
+	Blockchain Blockchain
+	// StatusCode is the HTTP status of the last response, set once the call
+	// has been sent. It stays 0 if no response was received.
+	StatusCode int
+}
+
+// Invoker performs a call, usually by passing it to the next interceptor.
+type Invoker func(ctx context.Context, call *Call) error
+
+// Interceptor wraps every SDK call. It may inspect or modify the call, return
+// early without invoking next, or observe the error next returns. Retries
+// happen inside next, so an interceptor runs once per call.
+type Interceptor func(ctx context.Context, call *Call, next Invoker) error
+
+// WithInterceptors appends interceptors to the chain. The first interceptor
+// is the outermost one.
+func WithInterceptors(interceptors ...Interceptor) ClientOption {
+	return func(c *TapestryClient) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
+// AddInterceptors appends interceptors to the chain. It must be called before
+// the client is used concurrently.
+func (c *TapestryClient) AddInterceptors(interceptors ...Interceptor) {
+	c.interceptors = append(c.interceptors, interceptors...)
+}
+
+// intercept runs call through the interceptor chain and finally executes it.
+func (c *TapestryClient) intercept(ctx context.Context, op operation, call *Call) error {
+	invoke := func(ctx context.Context, call *Call) error {
+		return c.execute(ctx, op, call)
+	}
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := c.interceptors[i], invoke
+		invoke = func(ctx context.Context, call *Call) error {
+			return interceptor(ctx, call, next)
+		}
+	}
+	return invoke(ctx, call)
+}
@@ -0,0 +1,113 @@
+package tapestry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInterceptors_WrapEveryCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Correlation-Id"); got != "corr-42" {
+			t.Errorf("X-Correlation-Id = %q, want %q", got, "corr-42")
+		}
+		if r.URL.Path == "/comments" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"invalid comment"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"c1","namespace":"ns"}`))
+	}))
+	defer server.Close()
+
+	var order []string
+	var seen []*Call
+	var observed error
+	client, err := New("key",
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithInterceptors(
+			func(ctx context.Context, call *Call, next Invoker) error {
+				order = append(order, "outer")
+				err := next(ctx, call)
+				observed = err
+				return err
+			},
+			func(ctx context.Context, call *Call, next Invoker) error {
+				order = append(order, "inner")
+				call.Header.Set("X-Correlation-Id", "corr-42")
+				seen = append(seen, call)
+				return next(ctx, call)
+			},
+		),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	content, err := client.FindOrCreateContent(ctx, "p1", "c1", []ContentProperty{{Key: "title", Value: "Hello"}})
+	if err != nil {
+		t.Fatalf("FindOrCreateContent() error = %v", err)
+	}
+	if strings.Join(order, ",") != "outer,inner" {
+		t.Errorf("interceptor order = %v, want outer,inner", order)
+	}
+
+	call := seen[0]
+	if call.Operation != "contents.findOrCreate" || call.ClientMethod != "FindOrCreateContent" ||
+		call.Method != http.MethodPost || call.URL.Path != "/contents/findOrCreate" {
+		t.Errorf("unexpected call: %s %s %s %s", call.Operation, call.ClientMethod, call.Method, call.URL)
+	}
+	if call.Execution != ExecutionFastUnconfirmed || call.Blockchain != DefaultBlockchain {
+		t.Errorf("call execution = %q, blockchain = %q; want client defaults", call.Execution, call.Blockchain)
+	}
+	if req, ok := call.Request.(FindOrCreateContentRequest); !ok || req.ID != "c1" || req.ProfileID != "p1" {
+		t.Errorf("call.Request = %#v, want the FindOrCreateContentRequest", call.Request)
+	}
+	if resp, ok := call.Response.(*CreateOrUpdateContentResponse); !ok || resp != content {
+		t.Errorf("call.Response = %#v, want the decoded response", call.Response)
+	}
+	if call.StatusCode != http.StatusOK {
+		t.Errorf("call.StatusCode = %d, want 200", call.StatusCode)
+	}
+
+	_, err = client.CreateComment(ctx, CreateCommentOptions{ContentID: "c1", ProfileID: "p1", Text: "hi"})
+	if !errors.Is(observed, ErrValidation) || observed != err {
+		t.Errorf("interceptor observed %v, caller got %v; want the same ErrValidation", observed, err)
+	}
+	if call := seen[len(seen)-1]; call.StatusCode != http.StatusBadRequest {
+		t.Errorf("failed call.StatusCode = %d, want 400", call.StatusCode)
+	}
+}
+
+func TestInterceptors_ShortCircuit(t *testing.T) {
+	client, err := New("key",
+		WithHTTPClient(failingDoer{}),
+		WithInterceptors(func(ctx context.Context, call *Call, next Invoker) error {
+			if resp, ok := call.Response.(*ProfileResponse); ok {
+				resp.Profile = Profile{ID: "cached", Username: "from-interceptor"}
+				return nil
+			}
+			return next(ctx, call)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	profile, err := client.GetProfileByID(context.Background(), "cached")
+	if err != nil {
+		t.Fatalf("GetProfileByID() error = %v", err)
+	}
+	if profile.Profile.Username != "from-interceptor" {
+		t.Errorf("GetProfileByID() = %+v, want the interceptor's answer", profile)
+	}
+
+	if err := client.DeleteContent(context.Background(), "c1"); err == nil {
+		t.Error("DeleteContent() should reach the failing transport")
+	}
+}
@@ -0,0 +1,302 @@
+package tapestry
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// defaultIteratorPageSize is requested when IteratorOptions.PageSize is 0.
+const defaultIteratorPageSize = 20
+
+// IteratorOptions controls how an iterator walks the pages of a listing.
+type IteratorOptions struct {
+	// PageSize is the number of items requested per page. Defaults to 20.
+	PageSize int
+	// StartPage is the first page to fetch. Pages start at 1, the default.
+	StartPage int
+	// MaxItems stops the iteration after that many items. 0 means no limit.
+	MaxItems int
+	// Prefetch fetches the next page in the background while the current one
+	// is being consumed.
+	Prefetch bool
+}
+
+func (o IteratorOptions) pageSize() int {
+	if o.PageSize > 0 {
+		return o.PageSize
+	}
+	return defaultIteratorPageSize
+}
+
+func (o IteratorOptions) startPage() int {
+	if o.StartPage > 0 {
+		return o.StartPage
+	}
+	return 1
+}
+
+// pageInfo is what a listing reports about its pagination. Zero fields were
+// not reported.
+type pageInfo struct {
+	pageSize int
+	total    int
+}
+
+// pageFetcher loads one page.
+type pageFetcher[T any] func(ctx context.Context, page int) ([]T, pageInfo, error)
+
+type pageResult[T any] struct {
+	items []T
+	info  pageInfo
+	err   error
+}
+
+// pageIterator walks a paginated listing lazily, one page at a time. It stops
+// at the first page that is empty or shorter than the page size, or once the
+// reported total is reached.
+type pageIterator[T any] struct {
+	fetch    pageFetcher[T]
+	opts     IteratorOptions
+	page     int
+	items    []T
+	index    int
+	item     T
+	yielded  int
+	total    int
+	lastPage bool
+	err      error
+	pending  chan pageResult[T]
+}
+
+func newPageIterator[T any](opts IteratorOptions, fetch pageFetcher[T]) pageIterator[T] {
+	return pageIterator[T]{
+		fetch: fetch,
+		opts:  opts,
+		page:  opts.startPage(),
+		index: -1,
+	}
+}
+
+// Next advances to the next item, fetching a new page when needed. It returns
+// false when the listing is exhausted, MaxItems is reached or an error
+// occurred, which Err then reports.
+func (it *pageIterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil || (it.opts.MaxItems > 0 && it.yielded >= it.opts.MaxItems) {
+		return false
+	}
+
+	for it.index+1 >= len(it.items) {
+		if it.lastPage {
+			return false
+		}
+
+		result := it.nextPage(ctx)
+		if result.err != nil {
+			it.err = result.err
+			return false
+		}
+		it.items, it.index = result.items, -1
+
+		pageSize := result.info.pageSize
+		if pageSize <= 0 {
+			pageSize = it.opts.pageSize()
+		}
+		if result.info.total > 0 {
+			it.total = result.info.total
+		}
+		it.lastPage = len(result.items) == 0 || len(result.items) < pageSize ||
+			(it.total > 0 && it.Page()*pageSize >= it.total)
+		if it.opts.MaxItems > 0 && it.yielded+len(result.items) >= it.opts.MaxItems {
+			// no need for another page
+			it.lastPage = true
+		}
+
+		if it.opts.Prefetch && !it.lastPage {
+			it.prefetch(ctx)
+		}
+	}
+
+	it.index++
+	it.item = it.items[it.index]
+	it.yielded++
+	return true
+}
+
+// Item returns the current item. It is only valid after Next returned true.
+func (it *pageIterator[T]) Item() T {
+	return it.item
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *pageIterator[T]) Err() error {
+	return it.err
+}
+
+// Total returns the total number of items reported by the API, or 0 if it
+// does not report one.
+func (it *pageIterator[T]) Total() int {
+	return it.total
+}
+
+// Page returns the number of the page the current item belongs to.
+func (it *pageIterator[T]) Page() int {
+	return it.page - 1
+}
+
+func (it *pageIterator[T]) nextPage(ctx context.Context) pageResult[T] {
+	if it.pending != nil {
+		pending := it.pending
+		it.pending = nil
+
+		select {
+		case result := <-pending:
+			if result.err == nil {
+				it.page++
+				return result
+			}
+			if !errors.Is(result.err, context.Canceled) && !errors.Is(result.err, context.DeadlineExceeded) {
+				return result
+			}
+			// the prefetch ran with the context of an earlier Next call that
+			// has since ended, try again with the caller's context
+		case <-ctx.Done():
+			return pageResult[T]{err: ctx.Err()}
+		}
+	}
+
+	items, info, err := it.fetch(ctx, it.page)
+	if err != nil {
+		return pageResult[T]{err: err}
+	}
+	it.page++
+	return pageResult[T]{items: items, info: info}
+}
+
+func (it *pageIterator[T]) prefetch(ctx context.Context) {
+	pending := make(chan pageResult[T], 1)
+	it.pending = pending
+
+	page := it.page
+	go func() {
+		items, info, err := it.fetch(ctx, page)
+		pending <- pageResult[T]{items: items, info: info, err: err}
+	}()
+}
+
+// ContentsIterator walks every content item matched by a GetContents query.
+type ContentsIterator struct {
+	pageIterator[ContentListItem]
+}
+
+// IterateContents returns an iterator over every item GetContents would return
+// with opts, page by page. Pagination set through opts is replaced by the
+// iterator's own.
+//
+//	it := client.IterateContents(tapestry.IteratorOptions{PageSize: 50}, tapestry.WithProfileID(id))
+//	for it.Next(ctx) {
+//		item := it.Item()
+//	}
+//	if err := it.Err(); err != nil {
+//		// handle err
+//	}
+func (c *TapestryClient) IterateContents(opts IteratorOptions, contentsOpts ...GetContentsOption) *ContentsIterator {
+	pageSize := opts.pageSize()
+
+	return &ContentsIterator{newPageIterator(opts, func(ctx context.Context, page int) ([]ContentListItem, pageInfo, error) {
+		pageOpts := append(append([]GetContentsOption(nil), contentsOpts...),
+			WithPagination(strconv.Itoa(page), strconv.Itoa(pageSize)))
+
+		resp, err := c.GetContents(ctx, pageOpts...)
+		if err != nil {
+			return nil, pageInfo{}, err
+		}
+		return resp.Contents, pageInfo{pageSize: resp.PageSize}, nil
+	})}
+}
+
+// CommentsIterator walks every comment or reply of a listing.
+type CommentsIterator struct {
+	pageIterator[CommentData]
+}
+
+// IterateComments returns an iterator over every comment GetComments would
+// return for options. options.Page and options.PageSize are replaced by the
+// iterator's own.
+func (c *TapestryClient) IterateComments(opts IteratorOptions, options GetCommentsOptions) *CommentsIterator {
+	options.PageSize = opts.pageSize()
+
+	return &CommentsIterator{newPageIterator(opts, func(ctx context.Context, page int) ([]CommentData, pageInfo, error) {
+		pageOptions := options
+		pageOptions.Page = page
+
+		resp, err := c.GetComments(ctx, pageOptions)
+		if err != nil {
+			return nil, pageInfo{}, err
+		}
+		return resp.Comments, pageInfo{}, nil
+	})}
+}
+
+// IterateCommentReplies returns an iterator over every reply to commentID.
+// options.Page and options.PageSize are replaced by the iterator's own.
+func (c *TapestryClient) IterateCommentReplies(commentID string, opts IteratorOptions, options GetCommentRepliesOptions) *CommentsIterator {
+	options.PageSize = opts.pageSize()
+
+	return &CommentsIterator{newPageIterator(opts, func(ctx context.Context, page int) ([]CommentData, pageInfo, error) {
+		pageOptions := options
+		pageOptions.Page = page
+
+		resp, err := c.GetCommentReplies(ctx, commentID, pageOptions)
+		if err != nil {
+			return nil, pageInfo{}, err
+		}
+		return resp.Comments, pageInfo{}, nil
+	})}
+}
+
+// ProfilesIterator walks every profile of a follower or following listing.
+type ProfilesIterator struct {
+	pageIterator[ProfileDetails]
+}
+
+// IterateFollowers returns an iterator over every profile following
+// profileID.
+func (c *TapestryClient) IterateFollowers(profileID string, opts IteratorOptions) *ProfilesIterator {
+	pageSize := opts.pageSize()
+
+	return &ProfilesIterator{newPageIterator(opts, func(ctx context.Context, page int) ([]ProfileDetails, pageInfo, error) {
+		resp, err := c.GetFollowers(ctx, profileID, WithPage(page, pageSize))
+		if err != nil {
+			return nil, pageInfo{}, err
+		}
+		return resp.Profiles, pageInfo{pageSize: resp.PageSize, total: resp.TotalCount}, nil
+	})}
+}
+
+// IterateFollowing returns an iterator over every profile profileID follows.
+func (c *TapestryClient) IterateFollowing(profileID string, opts IteratorOptions) *ProfilesIterator {
+	pageSize := opts.pageSize()
+
+	return &ProfilesIterator{newPageIterator(opts, func(ctx context.Context, page int) ([]ProfileDetails, pageInfo, error) {
+		resp, err := c.GetFollowing(ctx, profileID, WithPage(page, pageSize))
+		if err != nil {
+			return nil, pageInfo{}, err
+		}
+		return resp.Profiles, pageInfo{pageSize: resp.PageSize, total: resp.TotalCount}, nil
+	})}
+}
+
+// IterateFollowingWhoFollow returns an iterator over every profile followed
+// by requestorID that also follows profileID.
+func (c *TapestryClient) IterateFollowingWhoFollow(profileID, requestorID string, opts IteratorOptions) *ProfilesIterator {
+	pageSize := opts.pageSize()
+
+	return &ProfilesIterator{newPageIterator(opts, func(ctx context.Context, page int) ([]ProfileDetails, pageInfo, error) {
+		resp, err := c.GetFollowingWhoFollow(ctx, profileID, requestorID, WithPage(page, pageSize))
+		if err != nil {
+			return nil, pageInfo{}, err
+		}
+		return resp.Profiles, pageInfo{pageSize: resp.PageSize, total: resp.TotalCount}, nil
+	})}
+}
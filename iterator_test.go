@@ -0,0 +1,173 @@
+package tapestry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newPagedServer serves total items split into pages, like /contents and
+// /comments do.
+func newPagedServer(t *testing.T, total int) *recordingServer {
+	return newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+		if page < 1 || pageSize < 1 {
+			t.Errorf("bad pagination in %s", r.URL)
+		}
+		if strings.HasSuffix(r.URL.Path, "/following-who-follow") && r.URL.Query().Get("requestorId") != "p2" {
+			t.Errorf("missing requestorId in %s", r.URL)
+		}
+		var contents []ContentListItem
+		var comments []CommentData
+		var profiles []ProfileDetails
+		for i := (page - 1) * pageSize; i < page*pageSize && i < total; i++ {
+			id := fmt.Sprintf("item-%d", i)
+			contents = append(contents, ContentListItem{Content: Content{ID: id}})
+			comments = append(comments, CommentData{Comment: Comment{ID: id}})
+			profiles = append(profiles, ProfileDetails{ID: id})
+		}
+
+		switch {
+		case r.URL.Path == "/contents/":
+			_ = json.NewEncoder(w).Encode(GetContentsResponse{Contents: contents, Page: page, PageSize: pageSize})
+		case strings.HasPrefix(r.URL.Path, "/profiles/"):
+			_ = json.NewEncoder(w).Encode(GetFollowersResponse{Profiles: profiles, Page: page, PageSize: pageSize, TotalCount: total})
+		default:
+			_ = json.NewEncoder(w).Encode(GetCommentsResponse{Comments: comments})
+		}
+	})
+}
+
+// requestedPages returns the pages requested from s, in order.
+func requestedPages(s *recordingServer) []int {
+	var pages []int
+	for _, req := range s.requests() {
+		page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+func TestContentsIterator(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int
+		opts      IteratorOptions
+		wantItems int
+		wantPages []int
+	}{
+		{name: "stops at short page", total: 5, opts: IteratorOptions{PageSize: 2}, wantItems: 5, wantPages: []int{1, 2, 3}},
+		{name: "stops at empty page", total: 4, opts: IteratorOptions{PageSize: 2}, wantItems: 4, wantPages: []int{1, 2, 3}},
+		{name: "empty listing", total: 0, opts: IteratorOptions{PageSize: 2}, wantItems: 0, wantPages: []int{1}},
+		{name: "max items", total: 10, opts: IteratorOptions{PageSize: 2, MaxItems: 3}, wantItems: 3, wantPages: []int{1, 2}},
+		{name: "start page", total: 5, opts: IteratorOptions{PageSize: 2, StartPage: 2}, wantItems: 3, wantPages: []int{2, 3}},
+		{name: "prefetch", total: 5, opts: IteratorOptions{PageSize: 2, Prefetch: true}, wantItems: 5, wantPages: []int{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newPagedServer(t, tt.total)
+			client := NewTapestryClientWithHTTPClient("key", server.URL, ExecutionFastUnconfirmed, "SOLANA", server.Client())
+
+			it := client.IterateContents(tt.opts, WithProfileID("p1"), WithPagination("7", "100"))
+			var ids []string
+			for it.Next(context.Background()) {
+				ids = append(ids, it.Item().Content.ID)
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("Err() = %v", err)
+			}
+
+			if len(ids) != tt.wantItems {
+				t.Fatalf("got %d items, want %d: %v", len(ids), tt.wantItems, ids)
+			}
+			first := (tt.opts.startPage() - 1) * tt.opts.PageSize
+			for i, id := range ids {
+				if want := fmt.Sprintf("item-%d", first+i); id != want {
+					t.Errorf("item %d = %s, want %s", i, id, want)
+				}
+			}
+			if got := requestedPages(server); fmt.Sprint(got) != fmt.Sprint(tt.wantPages) {
+				t.Errorf("requested pages %v, want %v", got, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestCommentsIterator(t *testing.T) {
+	server := newPagedServer(t, 7)
+	client := NewTapestryClientWithHTTPClient("key", server.URL, ExecutionFastUnconfirmed, "SOLANA", server.Client())
+	ctx := context.Background()
+
+	iterators := map[string]*CommentsIterator{
+		"comments": client.IterateComments(IteratorOptions{PageSize: 3, Prefetch: true}, GetCommentsOptions{ContentID: "c1"}),
+		"replies":  client.IterateCommentReplies("comment-1", IteratorOptions{PageSize: 3}, GetCommentRepliesOptions{}),
+	}
+	for name, it := range iterators {
+		count := 0
+		for it.Next(ctx) {
+			count++
+		}
+		if it.Err() != nil || count != 7 {
+			t.Errorf("%s: got %d items, err %v; want 7 items", name, count, it.Err())
+		}
+		if it.Next(ctx) {
+			t.Errorf("%s: Next() after the end returned true", name)
+		}
+	}
+}
+
+func TestIterator_StopsOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(GetCommentsResponse{Comments: make([]CommentData, 2)})
+	}))
+	defer server.Close()
+	client := NewTapestryClientWithHTTPClient("key", server.URL, ExecutionFastUnconfirmed, "SOLANA", server.Client())
+
+	it := client.IterateComments(IteratorOptions{PageSize: 2, Prefetch: true}, GetCommentsOptions{ContentID: "c1"})
+	count := 0
+	for it.Next(context.Background()) {
+		count++
+	}
+	var apiErr *APIError
+	if count != 2 || !errors.As(it.Err(), &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("got %d items and err %v, want 2 items and a 500", count, it.Err())
+	}
+}
+
+func TestProfilesIterator_StopsAtTotal(t *testing.T) {
+	server := newPagedServer(t, 6)
+	client := NewTapestryClientWithHTTPClient("key", server.URL, ExecutionFastUnconfirmed, "SOLANA", server.Client())
+	ctx := context.Background()
+
+	iterators := map[string]*ProfilesIterator{
+		"followers":          client.IterateFollowers("p1", IteratorOptions{PageSize: 3}),
+		"following":          client.IterateFollowing("p1", IteratorOptions{PageSize: 3}),
+		"followingWhoFollow": client.IterateFollowingWhoFollow("p1", "p2", IteratorOptions{PageSize: 3}),
+	}
+	for name, it := range iterators {
+		count := 0
+		for it.Next(ctx) {
+			count++
+		}
+		if it.Err() != nil || count != 6 || it.Total() != 6 {
+			t.Errorf("%s: got %d items of %d, err %v; want 6", name, count, it.Total(), it.Err())
+		}
+	}
+
+	// a full last page needs no extra request when the total is known
+	if got, want := fmt.Sprint(requestedPages(server)), "[1 2 1 2 1 2]"; got != want {
+		t.Errorf("requested pages %s, want %s", got, want)
+	}
+}
@@ -1,72 +1,41 @@
 package tapestry
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
 	"net/url"
 )
 
 type CreateLikeRequest struct {
 	StartId   string `json:"startId"`
-	Execution string `json:"execution"`
+	Execution string `json:"execution,omitempty"`
 }
 type DeleteLikeRequest struct {
-	StartId string `json:"startId"`
+	StartId   string `json:"startId"`
+	Execution string `json:"execution,omitempty"`
 }
 
-func (c *TapestryClient) CreateLike(ctx context.Context, contentID string, profile Profile) error {
-	uri := fmt.Sprintf("%s/likes/%s?apiKey=%s&username=%s", c.tapestryApiBaseUrl, contentID, c.apiKey, url.QueryEscape(profile.Username))
-
-	reqBody, err := json.Marshal(CreateLikeRequest{StartId: profile.ID, Execution: "FAST_UNCONFIRMED"})
-	if err != nil {
-		return fmt.Errorf("error encoding body: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, bytes.NewBuffer(reqBody))
+func (c *TapestryClient) CreateLike(ctx context.Context, contentID string, profile Profile, opts ...CallOption) error {
+	ctx, callOpts, err := c.prepareCall(ctx, opCreateLike, opts)
 	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
+		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
-	}
-	defer resp.Body.Close()
+	uri := c.endpoint(url.Values{"username": {profile.Username}}, "likes", contentID)
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	defer c.invalidate(ctx, contentCacheKey(contentID))
 
-	return nil
+	return c.doJSON(ctx, opCreateLike, uri, CreateLikeRequest{StartId: profile.ID, Execution: string(callOpts.execution)}, nil)
 }
 
-func (c *TapestryClient) DeleteLike(ctx context.Context, contentID string, profile Profile) error {
-	uri := fmt.Sprintf("%s/likes/%s?apiKey=%s&username=%s", c.tapestryApiBaseUrl, contentID, c.apiKey, url.QueryEscape(profile.Username))
-
-	reqBody, err := json.Marshal(DeleteLikeRequest{StartId: profile.ID})
+func (c *TapestryClient) DeleteLike(ctx context.Context, contentID string, profile Profile, opts ...CallOption) error {
+	ctx, callOpts, err := c.prepareCall(ctx, opDeleteLike, opts)
 	if err != nil {
-		return fmt.Errorf("error encoding body: %w", err)
+		return err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, uri, bytes.NewBuffer(reqBody))
-	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
+	uri := c.endpoint(url.Values{"username": {profile.Username}}, "likes", contentID)
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	defer c.invalidate(ctx, contentCacheKey(contentID))
 
-	return nil
+	return c.doJSON(ctx, opDeleteLike, uri, DeleteLikeRequest{StartId: profile.ID, Execution: string(callOpts.execution)}, nil)
 }
@@ -0,0 +1,194 @@
+package tapestry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLoaderWait     = 2 * time.Millisecond
+	defaultLoaderMaxBatch = 100
+)
+
+// ContentNotFoundError reports a content ID that a batch read could not
+// return. It matches ErrNotFound.
+type ContentNotFoundError struct {
+	ID string
+	// Reason is the error the API gave for the ID, if any.
+	Reason string
+}
+
+func (e *ContentNotFoundError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("tapestry: content %s not found", e.ID)
+	}
+	return fmt.Sprintf("tapestry: content %s not found: %s", e.ID, e.Reason)
+}
+
+func (e *ContentNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// LoaderOptions controls how a ContentLoader groups lookups into batches.
+type LoaderOptions struct {
+	// Wait is how long a batch collects lookups after the first one before it
+	// is sent. Defaults to 2ms.
+	Wait time.Duration
+	// MaxBatch sends a batch as soon as it holds that many distinct IDs.
+	// Defaults to 100.
+	MaxBatch int
+}
+
+// ContentLoader collects individual content lookups made within a short
+// window and reads them with one GetContentsByBatchIDs call, DataLoader
+// style. Lookups made with different API keys or base URLs, set with
+// WithCallAPIKey or WithCallBaseURL, go into separate batches. It is safe for
+// concurrent use.
+type ContentLoader struct {
+	client   *TapestryClient
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	batches map[string]*contentBatch
+}
+
+type contentBatch struct {
+	scope      string
+	ids        []string
+	seen       map[string]bool
+	client     *TapestryClient
+	ctx        context.Context
+	deadline   time.Time
+	timer      *time.Timer
+	dispatched bool
+
+	done    chan struct{}
+	results map[string]BatchResponseContentListItem
+	failed  map[string]string
+	err     error
+}
+
+// NewContentLoader returns a loader that batches lookups through c.
+func (c *TapestryClient) NewContentLoader(opts LoaderOptions) *ContentLoader {
+	l := newContentLoader(opts)
+	l.client = c
+	return l
+}
+
+// WithContentLoader makes GetContentByID go through a ContentLoader, so that
+// concurrent lookups of different IDs are sent as batch reads. Unknown IDs
+// then fail with a *ContentNotFoundError.
+func WithContentLoader(opts LoaderOptions) ClientOption {
+	return func(c *TapestryClient) {
+		c.contentLoader = newContentLoader(opts)
+	}
+}
+
+func newContentLoader(opts LoaderOptions) *ContentLoader {
+	l := &ContentLoader{wait: opts.Wait, maxBatch: opts.MaxBatch, batches: make(map[string]*contentBatch)}
+	if l.wait <= 0 {
+		l.wait = defaultLoaderWait
+	}
+	if l.maxBatch <= 0 {
+		l.maxBatch = defaultLoaderMaxBatch
+	}
+	return l
+}
+
+// Load returns the content with the given ID once the batch it joined has
+// been read. Every caller gets its own copy of the result. Giving up on ctx
+// does not cancel the batch for the other callers, but the batch is read
+// within the earliest deadline of the callers that joined it.
+func (l *ContentLoader) Load(ctx context.Context, id string) (*GetContentResponse, error) {
+	return l.load(ctx, l.client, id)
+}
+
+func (l *ContentLoader) load(ctx context.Context, client *TapestryClient, id string) (*GetContentResponse, error) {
+	scope := client.scopedKey(ctx, "")
+	l.mu.Lock()
+	b := l.batches[scope]
+	if b == nil {
+		b = &contentBatch{
+			scope:  scope,
+			seen:   make(map[string]bool),
+			client: client,
+			ctx:    context.WithoutCancel(ctx),
+			done:   make(chan struct{}),
+		}
+		l.batches[scope] = b
+		b.timer = time.AfterFunc(l.wait, func() { l.dispatch(b) })
+	}
+	if deadline, ok := ctx.Deadline(); ok && (b.deadline.IsZero() || deadline.Before(b.deadline)) {
+		b.deadline = deadline
+	}
+	if !b.seen[id] {
+		b.seen[id] = true
+		b.ids = append(b.ids, id)
+	}
+	full := len(b.ids) >= l.maxBatch
+	if full {
+		// later lookups start a new batch
+		delete(l.batches, scope)
+	}
+	l.mu.Unlock()
+
+	if full {
+		b.timer.Stop()
+		go l.dispatch(b)
+	}
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if b.err != nil {
+		return nil, b.err
+	}
+	item, ok := b.results[id]
+	if !ok {
+		return nil, &ContentNotFoundError{ID: id, Reason: b.failed[id]}
+	}
+	return &GetContentResponse{Content: item.Content, SocialCounts: item.SocialCounts}, nil
+}
+
+// dispatch sends b once, whether its timer fired or it filled up first.
+func (l *ContentLoader) dispatch(b *contentBatch) {
+	l.mu.Lock()
+	if b.dispatched {
+		l.mu.Unlock()
+		return
+	}
+	b.dispatched = true
+	if l.batches[b.scope] == b {
+		delete(l.batches, b.scope)
+	}
+	l.mu.Unlock()
+
+	defer close(b.done)
+
+	ctx := b.ctx
+	if !b.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, b.deadline)
+		defer cancel()
+	}
+	resp, err := b.client.GetContentsByBatchIDs(ctx, b.ids)
+	if err != nil {
+		b.err = err
+		return
+	}
+
+	b.results = make(map[string]BatchResponseContentListItem, len(resp.Successful))
+	for _, item := range resp.Successful {
+		b.results[item.Content.ID] = item
+	}
+	b.failed = make(map[string]string, len(resp.Failed))
+	for _, failed := range resp.Failed {
+		b.failed[failed.ID] = failed.Error
+	}
+}
@@ -0,0 +1,164 @@
+package tapestry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newBatchServer answers batch reads, failing IDs that start with "bad" and
+// leaving out IDs that start with "gone".
+func newBatchServer(t *testing.T) *recordingServer {
+	return newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var ids []string
+		_ = json.NewDecoder(r.Body).Decode(&ids)
+
+		var resp GetContentsByBatchIDsResponse
+		for _, id := range ids {
+			switch {
+			case strings.HasPrefix(id, "bad"):
+				resp.Failed = append(resp.Failed, BatchResponseFailedItem{ID: id, Error: "invalid id"})
+			case strings.HasPrefix(id, "gone"):
+			default:
+				resp.Successful = append(resp.Successful, BatchResponseContentListItem{Content: Content{ID: id, Title: "title " + id}})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// sortedBatches returns every batch s received as its sorted, comma separated
+// IDs, in sorted order.
+func sortedBatches(t *testing.T, s *recordingServer) []string {
+	var batches []string
+	for _, ids := range s.batchIDs(t) {
+		sort.Strings(ids)
+		batches = append(batches, strings.Join(ids, ","))
+	}
+	sort.Strings(batches)
+	return batches
+}
+
+func loadAll(load func(ctx context.Context, id string) (*GetContentResponse, error), ids []string) ([]*GetContentResponse, []error) {
+	results := make([]*GetContentResponse, len(ids))
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			results[i], errs[i] = load(context.Background(), id)
+		}(i, id)
+	}
+	wg.Wait()
+	return results, errs
+}
+
+func TestContentLoader_BatchesLookups(t *testing.T) {
+	server := newBatchServer(t)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	loader := client.NewContentLoader(LoaderOptions{Wait: 50 * time.Millisecond})
+
+	ids := []string{"a", "b", "a", "bad1", "gone1"}
+	results, errs := loadAll(loader.Load, ids)
+
+	if got := sortedBatches(t, server); len(got) != 1 || got[0] != "a,b,bad1,gone1" {
+		t.Fatalf("batches = %v, want one with a,b,bad1,gone1", got)
+	}
+	for i, id := range ids[:3] {
+		if errs[i] != nil || results[i].Content.Title != "title "+id {
+			t.Errorf("Load(%s) = %+v, %v", id, results[i], errs[i])
+		}
+	}
+	if results[0] == results[2] {
+		t.Errorf("callers of the same ID share the same response value")
+	}
+
+	var notFound *ContentNotFoundError
+	if !errors.As(errs[3], &notFound) || notFound.ID != "bad1" || notFound.Reason != "invalid id" || !errors.Is(errs[3], ErrNotFound) {
+		t.Errorf("Load(bad1) error = %v, want a ContentNotFoundError with the API reason", errs[3])
+	}
+	if !errors.As(errs[4], &notFound) || notFound.ID != "gone1" || !errors.Is(errs[4], ErrNotFound) {
+		t.Errorf("Load(gone1) error = %v, want a ContentNotFoundError", errs[4])
+	}
+}
+
+func TestContentLoader_MaxBatch(t *testing.T) {
+	server := newBatchServer(t)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	loader := client.NewContentLoader(LoaderOptions{Wait: time.Hour, MaxBatch: 2})
+
+	_, errs := loadAll(loader.Load, []string{"a", "b", "c", "d"})
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+	}
+	if got := sortedBatches(t, server); len(got) != 2 {
+		t.Errorf("batches = %v, want 2 full batches", got)
+	}
+}
+
+func TestContentLoader_CallerCancellation(t *testing.T) {
+	server := newBatchServer(t)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	loader := client.NewContentLoader(LoaderOptions{Wait: 50 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := loader.Load(ctx, "a"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Load() with a canceled context error = %v", err)
+	}
+	if resp, err := loader.Load(context.Background(), "b"); err != nil || resp.Content.ID != "b" {
+		t.Errorf("Load(b) = %+v, %v", resp, err)
+	}
+}
+
+func TestWithContentLoader_BatchesGetContentByID(t *testing.T) {
+	server := newBatchServer(t)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()),
+		WithContentLoader(LoaderOptions{Wait: 50 * time.Millisecond}))
+
+	results, errs := loadAll(client.GetContentByID, []string{"a", "b", "c"})
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatalf("GetContentByID() error = %v", errs[i])
+		}
+	}
+	if got := sortedBatches(t, server); len(got) != 1 || got[0] != "a,b,c" {
+		t.Errorf("batches = %v, want one with a,b,c", got)
+	}
+
+	if _, err := client.GetContentByID(context.Background(), "gone"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetContentByID(gone) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestContentLoader_KeepsCallerDeadline(t *testing.T) {
+	server := newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxRetryAfter: 10 * time.Second}))
+	loader := client.NewContentLoader(LoaderOptions{Wait: time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := loader.Load(ctx, "a"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Load() error = %v, want ErrRateLimited", err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("Load() waited %v despite a Retry-After beyond the deadline", elapsed)
+	}
+	if got := server.count(); got != 1 {
+		t.Errorf("server got %d requests, want 1", got)
+	}
+}
@@ -0,0 +1,106 @@
+package tapestry
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"time"
+)
+
+// debugEnv enables request and response dumps when set to "1".
+const debugEnv = "TAPESTRY_DEBUG"
+
+// WithDebugDumps logs every HTTP request and response in full, with the API
+// key redacted, at debug level, or at info level if the logger discards debug
+// records. Setting TAPESTRY_DEBUG=1 has the same effect. Without WithLogger,
+// dumps are written as text to standard error.
+func WithDebugDumps(enabled bool) ClientOption {
+	return func(c *TapestryClient) {
+		c.debugDumps = enabled
+	}
+}
+
+func debugFromEnv() bool {
+	return os.Getenv(debugEnv) == "1"
+}
+
+// newDumpLogger returns the logger used for dumps when none is configured.
+func newDumpLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// dumpLevel returns the level dumps are logged at: debug, unless the logger
+// discards it, since dumps were asked for explicitly.
+func (c *TapestryClient) dumpLevel(ctx context.Context) slog.Level {
+	if c.logger.Enabled(ctx, slog.LevelDebug) {
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
+
+// callStats collects what the logger reports about a call.
+type callStats struct {
+	status        int
+	requestBytes  int
+	responseBytes int
+}
+
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += n
+	return n, err
+}
+
+// logCall logs the outcome of a call at debug level.
+func (c *TapestryClient) logCall(ctx context.Context, call *Call, stats *callStats, duration time.Duration, err error) {
+	if c.logger == nil || !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", call.Operation),
+		slog.String("method", call.Method),
+		slog.String("endpoint", call.URL.Path),
+		slog.Int("status", stats.status),
+		slog.Duration("duration", duration),
+		slog.Int("request_bytes", stats.requestBytes),
+		slog.Int("response_bytes", stats.responseBytes),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "tapestry call", attrs...)
+}
+
+// doDumped sends req through do and, when debug dumps are enabled, logs the
+// request and response with the API key redacted.
+func (c *TapestryClient) doDumped(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if !c.debugDumps || c.logger == nil {
+		return c.do(req)
+	}
+	level := c.dumpLevel(ctx)
+
+	if dump, err := httputil.DumpRequestOut(req, true); err == nil {
+		c.logger.Log(ctx, level, "tapestry request dump", "dump", c.redact(ctx, string(dump)))
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if dump, err := httputil.DumpResponse(resp, true); err == nil {
+		c.logger.Log(ctx, level, "tapestry response dump", "dump", c.redact(ctx, string(dump)))
+	}
+
+	return resp, nil
+}
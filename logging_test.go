@@ -0,0 +1,167 @@
+package tapestry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func newLoggingTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message":"username already taken"}`))
+	}))
+}
+
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLogging_LogsCallSummary(t *testing.T) {
+	server := newLoggingTestServer()
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client, err := New(testAPIKey, WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, _ = client.FindOrCreateProfile(context.Background(), FindOrCreateProfileParameters{WalletAddress: testWallet, Username: "alice"})
+
+	entries := logEntries(t, &buf)
+	if len(entries) != 1 {
+		t.Fatalf("got %d log entries, want 1: %s", len(entries), buf.String())
+	}
+	entry := entries[0]
+	if entry["msg"] != "tapestry call" || entry["operation"] != "profiles.findOrCreate" ||
+		entry["status"] != float64(http.StatusBadRequest) || entry["endpoint"] != "/profiles/findOrCreate" {
+		t.Errorf("unexpected log entry: %v", entry)
+	}
+	if entry["request_bytes"].(float64) == 0 || entry["response_bytes"].(float64) == 0 {
+		t.Errorf("log entry is missing payload sizes: %v", entry)
+	}
+	if strings.Contains(buf.String(), "alice") {
+		t.Errorf("summary log contains the request body without dumps enabled")
+	}
+}
+
+func TestLogging_DebugDumps(t *testing.T) {
+	server := newLoggingTestServer()
+	defer server.Close()
+
+	for _, viaEnv := range []bool{false, true} {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		opts := []ClientOption{WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithLogger(logger), WithAPIKeyMode(APIKeyQuery)}
+		if viaEnv {
+			t.Setenv(debugEnv, "1")
+		} else {
+			opts = append(opts, WithDebugDumps(true))
+		}
+		client, err := New(testAPIKey, opts...)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		_, _ = client.FindOrCreateProfile(context.Background(), FindOrCreateProfileParameters{WalletAddress: testWallet, Username: "alice"})
+
+		var requestDump, responseDump string
+		for _, entry := range logEntries(t, &buf) {
+			switch entry["msg"] {
+			case "tapestry request dump":
+				requestDump, _ = entry["dump"].(string)
+			case "tapestry response dump":
+				responseDump, _ = entry["dump"].(string)
+			}
+		}
+		if !strings.Contains(requestDump, `"username":"alice"`) {
+			t.Errorf("env=%v: request dump does not contain the body: %q", viaEnv, requestDump)
+		}
+		if !strings.Contains(responseDump, "username already taken") {
+			t.Errorf("env=%v: response dump does not contain the body: %q", viaEnv, responseDump)
+		}
+		if strings.Contains(buf.String(), "secret-key") {
+			t.Errorf("env=%v: logs leak the API key: %s", viaEnv, buf.String())
+		}
+	}
+}
+
+func TestLogging_DebugDumpsWithoutLogger(t *testing.T) {
+	server := newLoggingTestServer()
+	defer server.Close()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	t.Setenv(debugEnv, "1")
+	client, err := New(testAPIKey, WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	os.Stderr = stderr
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, _ = client.FindOrCreateProfile(context.Background(), FindOrCreateProfileParameters{WalletAddress: testWallet, Username: "alice"})
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+
+	if !strings.Contains(string(out), "tapestry request dump") || !strings.Contains(string(out), "username already taken") {
+		t.Errorf("no dumps written to stderr: %q", out)
+	}
+	if strings.Contains(string(out), testAPIKey) {
+		t.Errorf("stderr leaks the API key: %q", out)
+	}
+}
+
+func TestLogging_DebugDumpsWithInfoLogger(t *testing.T) {
+	server := newLoggingTestServer()
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	client, err := New(testAPIKey, WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithLogger(logger), WithDebugDumps(true))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, _ = client.FindOrCreateProfile(context.Background(), FindOrCreateProfileParameters{WalletAddress: testWallet, Username: "alice"})
+
+	var dumps int
+	for _, entry := range logEntries(t, &buf) {
+		switch entry["msg"] {
+		case "tapestry request dump", "tapestry response dump":
+			dumps++
+			if entry["level"] != "INFO" {
+				t.Errorf("dump logged at %v, want INFO", entry["level"])
+			}
+		}
+	}
+	if dumps != 2 {
+		t.Errorf("got %d dumps, want 2: %s", dumps, buf.String())
+	}
+}
@@ -0,0 +1,63 @@
+package tapestry
+
+import (
+	"errors"
+	"time"
+)
+
+// Status classes reported to Metrics.
+const (
+	StatusClass2xx         = "2xx"
+	StatusClass4xx         = "4xx"
+	StatusClass5xx         = "5xx"
+	StatusClassRateLimited = "rate_limited"
+	StatusClassError       = "error"
+)
+
+// Metrics receives measurements for every SDK call. The operation argument
+// names the call, for example "contents.findOrCreate". Implementations must
+// be safe for concurrent use. The tapestryprom module provides a Prometheus
+// implementation.
+type Metrics interface {
+	// ObserveCall records one call, including its retries and rate limit
+	// waits. statusClass is one of the StatusClass constants.
+	ObserveCall(operation string, statusClass string, duration time.Duration)
+	// IncRetry records that an attempt of operation is being retried.
+	IncRetry(operation string)
+	// ObserveRateLimitWait records how long the client-side rate limiter
+	// held back an attempt.
+	ObserveRateLimitWait(operation string, wait time.Duration)
+}
+
+// WithMetrics reports measurements for every call to metrics.
+func WithMetrics(metrics Metrics) ClientOption {
+	return func(c *TapestryClient) {
+		c.metrics = metrics
+	}
+}
+
+// StatusClass classifies the error returned by an SDK call.
+func StatusClass(err error) string {
+	if err == nil {
+		return StatusClass2xx
+	}
+
+	var limitErr *RateLimitError
+	if errors.As(err, &limitErr) {
+		return StatusClassRateLimited
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode >= 500:
+			return StatusClass5xx
+		case apiErr.StatusCode >= 400:
+			return StatusClass4xx
+		case apiErr.StatusCode >= 200 && apiErr.StatusCode < 300:
+			return StatusClass2xx
+		}
+	}
+
+	return StatusClassError
+}
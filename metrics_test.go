@@ -0,0 +1,107 @@
+package tapestry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type recordingMetrics struct {
+	mu     sync.Mutex
+	calls  []string
+	retry  map[string]int
+	waited map[string]time.Duration
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{retry: map[string]int{}, waited: map[string]time.Duration{}}
+}
+
+func (m *recordingMetrics) ObserveCall(operation string, statusClass string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, operation+" "+statusClass)
+}
+
+func (m *recordingMetrics) IncRetry(operation string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retry[operation]++
+}
+
+func (m *recordingMetrics) ObserveRateLimitWait(operation string, wait time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.waited[operation] += wait
+}
+
+func TestMetrics_RecordsCalls(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/comments/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case atomic.AddInt32(&calls, 1) == 1:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	metrics := newRecordingMetrics()
+	client, err := New("key",
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithMetrics(metrics),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}),
+		WithRateLimiter(NewRateLimiter(RateLimiterConfig{Read: RateLimit{Rate: 20, Burst: 1}})),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := client.GetFollowers(ctx, "p1"); err != nil {
+		t.Fatalf("GetFollowers() error = %v", err)
+	}
+	if _, err := client.GetCommentByID(ctx, "missing", ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetCommentByID() error = %v, want ErrNotFound", err)
+	}
+
+	want := []string{"profiles.followers 2xx", "comments.get 4xx"}
+	if fmt.Sprint(metrics.calls) != fmt.Sprint(want) {
+		t.Errorf("observed calls %v, want %v", metrics.calls, want)
+	}
+	if metrics.retry["profiles.followers"] != 1 {
+		t.Errorf("retries = %v, want 1 for profiles.followers", metrics.retry)
+	}
+	if metrics.waited["profiles.followers"] == 0 || metrics.waited["comments.get"] == 0 {
+		t.Errorf("rate limit waits = %v, want waits for both operations", metrics.waited)
+	}
+}
+
+func TestStatusClass(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, StatusClass2xx},
+		{&APIError{StatusCode: http.StatusBadRequest}, StatusClass4xx},
+		{fmt.Errorf("wrapped: %w", &APIError{StatusCode: http.StatusServiceUnavailable}), StatusClass5xx},
+		{fmt.Errorf("error making request: %w", &RateLimitError{}), StatusClassRateLimited},
+		{context.DeadlineExceeded, StatusClassError},
+	}
+
+	for _, tt := range tests {
+		if got := StatusClass(tt.err); got != tt.want {
+			t.Errorf("StatusClass(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package tapestry
+
+import "net/http"
+
+// operation describes one logical SDK call.
+type operation struct {
+	// name identifies the call, for example "contents.findOrCreate".
+	name string
+	// fn is the client method making the call, for example "FindOrCreateContent".
+	fn     string
+	method string
+	// idempotent reports whether the call may be replayed safely when a
+	// previous attempt may already have reached the server.
+	idempotent bool
+	// write marks calls that change state on the server. Writes and reads
+	// draw from separate rate limit budgets.
+	write bool
+	// executions lists the execution modes the endpoint accepts. It is empty
+	// for endpoints that take none.
+	executions []Execution
+}
+
+var (
+	opFindOrCreateProfile   = operation{name: "profiles.findOrCreate", fn: "FindOrCreateProfile", method: http.MethodPost, idempotent: true, write: true, executions: writeExecutions}
+	opUpdateProfile         = operation{name: "profiles.update", fn: "UpdateProfile", method: http.MethodPut, idempotent: true, write: true, executions: writeExecutions}
+	opGetProfile            = operation{name: "profiles.get", fn: "GetProfileByID", method: http.MethodGet, idempotent: true}
+	opGetFollowers          = operation{name: "profiles.followers", fn: "GetFollowers", method: http.MethodGet, idempotent: true}
+	opGetFollowing          = operation{name: "profiles.following", fn: "GetFollowing", method: http.MethodGet, idempotent: true}
+	opGetFollowingWhoFollow = operation{name: "profiles.followingWhoFollow", fn: "GetFollowingWhoFollow", method: http.MethodGet, idempotent: true}
+	opGetSuggestedProfiles  = operation{name: "profiles.suggested", fn: "GetSuggestedProfiles", method: http.MethodGet, idempotent: true}
+
+	opFindOrCreateContent   = operation{name: "contents.findOrCreate", fn: "FindOrCreateContent", method: http.MethodPost, idempotent: true, write: true, executions: writeExecutions}
+	opUpdateContent         = operation{name: "contents.update", fn: "UpdateContent", method: http.MethodPut, idempotent: true, write: true, executions: writeExecutions}
+	opDeleteContent         = operation{name: "contents.delete", fn: "DeleteContent", method: http.MethodDelete, idempotent: true, write: true}
+	opGetContent            = operation{name: "contents.get", fn: "GetContentByID", method: http.MethodGet, idempotent: true}
+	opGetContentsByBatchIDs = operation{name: "contents.batchRead", fn: "GetContentsByBatchIDs", method: http.MethodPost, idempotent: true}
+	opGetContents           = operation{name: "contents.list", fn: "GetContents", method: http.MethodGet, idempotent: true}
+
+	opCreateComment     = operation{name: "comments.create", fn: "CreateComment", method: http.MethodPost, write: true, executions: writeExecutions}
+	opGetComments       = operation{name: "comments.list", fn: "GetComments", method: http.MethodGet, idempotent: true}
+	opGetComment        = operation{name: "comments.get", fn: "GetCommentByID", method: http.MethodGet, idempotent: true}
+	opDeleteComment     = operation{name: "comments.delete", fn: "DeleteComment", method: http.MethodDelete, idempotent: true, write: true}
+	opUpdateComment     = operation{name: "comments.update", fn: "UpdateComment", method: http.MethodPut, idempotent: true, write: true}
+	opGetCommentReplies = operation{name: "comments.replies", fn: "GetCommentReplies", method: http.MethodGet, idempotent: true}
+
+	opCreateLike = operation{name: "likes.create", fn: "CreateLike", method: http.MethodPost, write: true, executions: writeExecutions}
+	opDeleteLike = operation{name: "likes.delete", fn: "DeleteLike", method: http.MethodDelete, idempotent: true, write: true, executions: writeExecutions}
+
+	opAddFollower    = operation{name: "followers.add", fn: "AddFollower", method: http.MethodPost, write: true, executions: writeExecutions}
+	opRemoveFollower = operation{name: "followers.remove", fn: "RemoveFollower", method: http.MethodPost, idempotent: true, write: true}
+)
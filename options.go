@@ -0,0 +1,164 @@
+package tapestry
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the production Tapestry API.
+	DefaultBaseURL = "https://api.usetapestry.dev/api/v1"
+	// DefaultBlockchain is used when WithBlockchain is not given.
+	DefaultBlockchain = BlockchainSolana
+
+	defaultUserAgent = "tapestry-go"
+)
+
+// ClientOption configures a TapestryClient created with New.
+type ClientOption func(*TapestryClient)
+
+// New creates a client for the Tapestry API. Without options it talks to
+// DefaultBaseURL on DefaultBlockchain with ExecutionFastUnconfirmed writes
+// through http.DefaultClient. It fails if the base URL is not an absolute
+// http or https URL.
+func New(apiKey string, opts ...ClientOption) (*TapestryClient, error) {
+	c := newClient(apiKey, opts...)
+
+	if err := validateBaseURL(c.tapestryApiBaseUrl); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func newClient(apiKey string, opts ...ClientOption) *TapestryClient {
+	c := &TapestryClient{
+		tapestryApiBaseUrl: DefaultBaseURL,
+		apiKey:             apiKey,
+		execution:          ExecutionFastUnconfirmed,
+		blockchain:         DefaultBlockchain,
+		httpClient:         http.DefaultClient,
+		userAgent:          defaultUserAgent,
+		apiKeyState:        &apiKeyState{},
+		debugDumps:         debugFromEnv(),
+		cacheFills:         &cacheFills{},
+		flights:            &flightGroup{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.debugDumps && c.logger == nil {
+		c.logger = newDumpLogger()
+	}
+
+	c.tapestryApiBaseUrl = strings.TrimRight(strings.TrimSpace(c.tapestryApiBaseUrl), "/")
+
+	return c
+}
+
+func validateBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid base URL %q: missing host", baseURL)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("invalid base URL %q: must not have a query or fragment", baseURL)
+	}
+	return nil
+}
+
+// WithBaseURL sets the API root, for example "https://api.usetapestry.dev/api/v1".
+// Trailing slashes are removed.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *TapestryClient) {
+		c.tapestryApiBaseUrl = baseURL
+	}
+}
+
+// WithExecution sets the default execution mode for writes. WithCallExecution
+// overrides it for a single call.
+func WithExecution(execution Execution) ClientOption {
+	return func(c *TapestryClient) {
+		c.execution = execution
+	}
+}
+
+// WithBlockchain sets the blockchain of new profiles and the wallet address
+// format FindOrCreateProfile and GetSuggestedProfiles expect.
+func WithBlockchain(blockchain Blockchain) ClientOption {
+	return func(c *TapestryClient) {
+		c.blockchain = blockchain
+	}
+}
+
+// WithHTTPClient sends every request through httpClient instead of
+// http.DefaultClient.
+func WithHTTPClient(httpClient HTTPDoer) ClientOption {
+	return func(c *TapestryClient) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithUserAgent sets the User-Agent header, "tapestry-go" by default.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *TapestryClient) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithTimeout bounds every call whose context has no deadline of its own.
+// Retries and rate limit waits count against the timeout.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *TapestryClient) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetryPolicy is the option form of SetRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *TapestryClient) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithRateLimiter is the option form of SetRateLimiter.
+func WithRateLimiter(limiter *RateLimiter) ClientOption {
+	return func(c *TapestryClient) {
+		c.rateLimiter = limiter
+	}
+}
+
+// WithAPIKeyMode is the option form of SetAPIKeyMode.
+func WithAPIKeyMode(mode APIKeyMode) ClientOption {
+	return func(c *TapestryClient) {
+		c.apiKeyMode = mode
+	}
+}
+
+// WithLogger makes the client log every call with its status, duration and
+// payload sizes at debug level, as well as retries and authentication
+// fallbacks.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *TapestryClient) {
+		c.logger = logger
+	}
+}
+
+// WithHooks registers callbacks around every HTTP attempt.
+func WithHooks(hooks Hooks) ClientOption {
+	return func(c *TapestryClient) {
+		c.hooks = hooks
+	}
+}
@@ -0,0 +1,197 @@
+package tapestry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew_ValidatesBaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+		wantErr bool
+	}{
+		{baseURL: "https://api.usetapestry.dev/api/v1", want: "https://api.usetapestry.dev/api/v1"},
+		{baseURL: "https://api.usetapestry.dev/api/v1///", want: "https://api.usetapestry.dev/api/v1"},
+		{baseURL: " http://localhost:8080/ ", want: "http://localhost:8080"},
+		{baseURL: "api.usetapestry.dev/api/v1", wantErr: true},
+		{baseURL: "ftp://api.usetapestry.dev", wantErr: true},
+		{baseURL: "https://", wantErr: true},
+		{baseURL: "https://api.usetapestry.dev?apiKey=x", wantErr: true},
+		{baseURL: "://bad", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		client, err := New("key", WithBaseURL(tt.baseURL))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("New(WithBaseURL(%q)) error = %v, wantErr %v", tt.baseURL, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && client.tapestryApiBaseUrl != tt.want {
+			t.Errorf("New(WithBaseURL(%q)) base URL = %q, want %q", tt.baseURL, client.tapestryApiBaseUrl, tt.want)
+		}
+	}
+}
+
+func TestNew_Defaults(t *testing.T) {
+	client, err := New("key")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if client.tapestryApiBaseUrl != DefaultBaseURL || client.blockchain != DefaultBlockchain ||
+		client.execution != ExecutionFastUnconfirmed || client.httpClient != http.DefaultClient {
+		t.Errorf("New() = %+v, want defaults", client)
+	}
+}
+
+func TestNew_Options(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("User-Agent"); got != "my-app/1.0" {
+			t.Errorf("User-Agent = %q, want %q", got, "my-app/1.0")
+		}
+		if r.Header.Get("X-Correlation-Id") != "corr-1" {
+			t.Errorf("header added by OnRequest hook is missing")
+		}
+		if r.Header.Get("X-Attempt") == "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var requests, responses, retries int
+	client, err := New("key",
+		WithBaseURL(server.URL+"/"),
+		WithHTTPClient(server.Client()),
+		WithUserAgent("my-app/1.0"),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}),
+		WithHooks(Hooks{
+			OnRequest: func(ctx context.Context, operation string, req *http.Request) {
+				requests++
+				req.Header.Set("X-Correlation-Id", "corr-1")
+				if requests > 1 {
+					req.Header.Set("X-Attempt", "retry")
+				}
+			},
+			OnResponse: func(ctx context.Context, operation string, req *http.Request, resp *http.Response, err error, duration time.Duration) {
+				responses++
+			},
+			OnRetry: func(ctx context.Context, operation string, attempt int, wait time.Duration) {
+				retries++
+				if operation != "profiles.followers" {
+					t.Errorf("OnRetry operation = %q, want %q", operation, "profiles.followers")
+				}
+			},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := client.GetFollowers(context.Background(), "p1"); err != nil {
+		t.Fatalf("GetFollowers() error = %v", err)
+	}
+	if requests != 2 || responses != 2 || retries != 1 {
+		t.Errorf("hooks saw %d requests, %d responses, %d retries; want 2, 2, 1", requests, responses, retries)
+	}
+}
+
+func TestHooks_NeverSeeAPIKey(t *testing.T) {
+	const apiKey = "SECRETKEY"
+	modes := map[string]APIKeyMode{"auto": APIKeyAuto, "header": APIKeyHeader, "query": APIKeyQuery}
+	for name, mode := range modes {
+		t.Run(name, func(t *testing.T) {
+			server := newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Values("X-Hook"); len(got) != 1 {
+					t.Errorf("X-Hook = %q, want one value", got)
+				}
+				switch {
+				case mode == APIKeyAuto && r.Header.Get(apiKeyHeader) != "":
+					w.WriteHeader(http.StatusUnauthorized)
+				case r.Header.Get("X-Attempt") == "":
+					w.WriteHeader(http.StatusServiceUnavailable)
+				default:
+					_, _ = w.Write([]byte(`{}`))
+				}
+			})
+
+			leaks := func(hook string, req *http.Request) {
+				t.Helper()
+				if strings.Contains(req.URL.String(), apiKey) || strings.Contains(fmt.Sprint(req.Header), apiKey) {
+					t.Errorf("%s saw the API key in %s %v", hook, req.URL, req.Header)
+				}
+			}
+			attempts := 0
+			client, _ := New(apiKey,
+				WithBaseURL(server.URL),
+				WithHTTPClient(server.Client()),
+				WithAPIKeyMode(mode),
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}),
+				WithHooks(Hooks{
+					OnRequest: func(ctx context.Context, operation string, req *http.Request) {
+						attempts++
+						leaks("OnRequest", req)
+						req.Header.Add("X-Hook", "1")
+						if attempts > 1 {
+							req.Header.Set("X-Attempt", "retry")
+						}
+					},
+					OnResponse: func(ctx context.Context, operation string, req *http.Request, resp *http.Response, err error, duration time.Duration) {
+						leaks("OnResponse", req)
+						if resp != nil {
+							leaks("OnResponse resp.Request", resp.Request)
+						}
+					},
+				}),
+			)
+
+			if _, err := client.GetFollowers(context.Background(), "p1"); err != nil {
+				t.Fatalf("GetFollowers() error = %v", err)
+			}
+			if attempts != 2 {
+				t.Errorf("OnRequest ran %d times, want 2", attempts)
+			}
+		})
+	}
+}
+
+func TestNew_WithTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client, err := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := client.DeleteComment(context.Background(), "c1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("DeleteComment() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestNewTapestryClient_NormalizesBaseURL(t *testing.T) {
+	client := NewTapestryClient("key", "https://api.usetapestry.dev/api/v1/", ExecutionConfirmedParsed, "SOLANA")
+	if client.tapestryApiBaseUrl != "https://api.usetapestry.dev/api/v1" {
+		t.Errorf("base URL = %q, want trailing slash removed", client.tapestryApiBaseUrl)
+	}
+}
+
+func TestNewTapestryClient_TakesBlockchainString(t *testing.T) {
+	blockchain := "ETHEREUM"
+	client := NewTapestryClient("key", DefaultBaseURL, ExecutionConfirmedParsed, blockchain)
+	if client.blockchain != BlockchainEthereum {
+		t.Errorf("blockchain = %q, want %q", client.blockchain, BlockchainEthereum)
+	}
+}
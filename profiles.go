@@ -2,10 +2,8 @@ package tapestry
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"strings"
+	"net/url"
+	"strconv"
 )
 
 type Profile struct {
@@ -53,11 +51,17 @@ type UpdateProfileRequest struct {
 }
 
 type GetFollowersResponse struct {
-	Profiles []ProfileDetails `json:"profiles"`
+	Profiles   []ProfileDetails `json:"profiles"`
+	Page       int              `json:"page,omitempty"`
+	PageSize   int              `json:"pageSize,omitempty"`
+	TotalCount int              `json:"totalCount,omitempty"`
 }
 
 type GetFollowingResponse struct {
-	Profiles []ProfileDetails `json:"profiles"`
+	Profiles   []ProfileDetails `json:"profiles"`
+	Page       int              `json:"page,omitempty"`
+	PageSize   int              `json:"pageSize,omitempty"`
+	TotalCount int              `json:"totalCount,omitempty"`
 }
 
 type ProfileDetails struct {
@@ -69,7 +73,10 @@ type ProfileDetails struct {
 }
 
 type GetFollowingWhoFollowResponse struct {
-	Profiles []ProfileDetails `json:"profiles"`
+	Profiles   []ProfileDetails `json:"profiles"`
+	Page       int              `json:"page,omitempty"`
+	PageSize   int              `json:"pageSize,omitempty"`
+	TotalCount int              `json:"totalCount,omitempty"`
 }
 
 type SuggestedProfileValue struct {
@@ -88,206 +95,190 @@ type GetSuggestedProfilesResponse struct {
 	Profiles map[string]SuggestedProfileValue
 }
 
-func (c *TapestryClient) FindOrCreateProfile(ctx context.Context, params FindOrCreateProfileParameters) (*ProfileResponse, error) {
-	reqData := FindOrCreateProfileRequest{
-		FindOrCreateProfileParameters: params,
-		Execution:                     string(c.execution),
-		Blockchain:                    c.blockchain,
-	}
-
-	url := fmt.Sprintf("%s/profiles/findOrCreate?apiKey=%s", c.tapestryApiBaseUrl, c.apiKey)
-
-	jsonBody, err := json.Marshal(reqData)
+// FindOrCreateProfile returns the profile with params.ID, creating it if
+// needed. params is checked before anything is sent, see
+// FindOrCreateProfileParameters.Validate, and a non-empty WalletAddress is
+// normalized for the client's blockchain, see Blockchain.NormalizeAddress.
+// Invalid parameters fail with a *ValidationError.
+func (c *TapestryClient) FindOrCreateProfile(ctx context.Context, params FindOrCreateProfileParameters, opts ...CallOption) (*ProfileResponse, error) {
+	ctx, callOpts, err := c.prepareCall(ctx, opFindOrCreateProfile, opts)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling request: %w", err)
+		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(string(jsonBody)))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+	var v validator
+	params.validate(&v)
+	blockchain := c.blockchainFor(ctx)
+	if params.WalletAddress != "" {
+		address, err := v.walletAddress("walletAddress", blockchain, params.WalletAddress)
+		if err != nil {
+			return nil, err
+		}
+		params.WalletAddress = address
 	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+	if err := v.err(); err != nil {
+		return nil, err
 	}
 
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	reqData := FindOrCreateProfileRequest{
+		FindOrCreateProfileParameters: params,
+		Execution:                     string(callOpts.execution),
+		Blockchain:                    string(blockchain),
 	}
 
+	uri := c.endpoint(nil, "profiles", "findOrCreate")
+
 	var profileResp ProfileResponse
-	if err := json.NewDecoder(resp.Body).Decode(&profileResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if err := c.doJSON(ctx, opFindOrCreateProfile, uri, reqData, &profileResp); err != nil {
+		c.invalidate(ctx, profileCacheKey(params.ID))
+		return nil, err
 	}
+	c.invalidate(ctx, profileCacheKey(params.ID), profileCacheKey(profileResp.Profile.ID))
 
 	return &profileResp, nil
 }
 
-func (c *TapestryClient) UpdateProfile(ctx context.Context, id string, reqData UpdateProfileParameters) error {
-	url := fmt.Sprintf("%s/profiles/%s?apiKey=%s", c.tapestryApiBaseUrl, id, c.apiKey)
-
-	jsonBody, err := json.Marshal(UpdateProfileRequest{
-		UpdateProfileParameters: reqData,
-		Execution:               string(c.execution),
-	})
+func (c *TapestryClient) UpdateProfile(ctx context.Context, id string, reqData UpdateProfileParameters, opts ...CallOption) error {
+	ctx, callOpts, err := c.prepareCall(ctx, opUpdateProfile, opts)
 	if err != nil {
-		return fmt.Errorf("error marshaling request: %w", err)
+		return err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, strings.NewReader(string(jsonBody)))
-	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
+	var v validator
+	v.required("id", id)
+	reqData.validate(&v)
+	if err := v.err(); err != nil {
+		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
-	}
-	defer resp.Body.Close()
+	uri := c.endpoint(nil, "profiles", id)
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	defer c.invalidate(ctx, profileCacheKey(id))
 
-	return nil
+	return c.doJSON(ctx, opUpdateProfile, uri, UpdateProfileRequest{
+		UpdateProfileParameters: reqData,
+		Execution:               string(callOpts.execution),
+	}, nil)
 }
 
+// GetProfileByID returns an error matching ErrNotFound when the profile does
+// not exist.
 func (c *TapestryClient) GetProfileByID(ctx context.Context, id string) (*ProfileResponse, error) {
-	url := fmt.Sprintf("%s/profiles/%s?apiKey=%s", c.tapestryApiBaseUrl, id, c.apiKey)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
+	uri := c.endpoint(nil, "profiles", id)
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+	var profileResp ProfileResponse
+	if c.cacheGet(ctx, profileCacheKey(id), &profileResp) {
+		return &profileResp, nil
 	}
 
-	defer resp.Body.Close()
+	profileResp, err := coalesce(ctx, c.flights, c.scopedKey(ctx, profileCacheKey(id)), func(ctx context.Context) (ProfileResponse, error) {
+		fill := c.startFill(ctx, profileCacheKey(id))
+		defer fill.done()
 
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusNotFound {
-			return nil, nil // ok but not found
+		var profileResp ProfileResponse
+		if err := c.doJSON(ctx, opGetProfile, uri, nil, &profileResp); err != nil {
+			return profileResp, err
 		}
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+		fill.set(profileResp)
 
-	var profileResp ProfileResponse
-	if err := json.NewDecoder(resp.Body).Decode(&profileResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+		return profileResp, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &profileResp, nil
 }
 
-func (c *TapestryClient) GetFollowers(ctx context.Context, profileID string) (*GetFollowersResponse, error) {
-	url := fmt.Sprintf("%s/profiles/%s/followers?apiKey=%s", c.tapestryApiBaseUrl, profileID, c.apiKey)
+// PageOption selects a page of a follower or following listing.
+type PageOption func(*pageParams)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
+type pageParams struct {
+	page     int
+	pageSize int
+}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+// WithPage requests the given page, starting at 1, of pageSize profiles.
+// Without it the API returns its first page at its default size.
+func WithPage(page, pageSize int) PageOption {
+	return func(p *pageParams) {
+		p.page = page
+		p.pageSize = pageSize
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+// pageQuery adds the page selected by opts to params.
+func pageQuery(params url.Values, opts []PageOption) url.Values {
+	var p pageParams
+	for _, opt := range opts {
+		opt(&p)
 	}
 
-	var followersResp GetFollowersResponse
-	if err := json.NewDecoder(resp.Body).Decode(&followersResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if p.page > 0 {
+		params.Add("page", strconv.Itoa(p.page))
+	}
+	if p.pageSize > 0 {
+		params.Add("pageSize", strconv.Itoa(p.pageSize))
 	}
 
-	return &followersResp, nil
+	return params
 }
 
-func (c *TapestryClient) GetFollowing(ctx context.Context, profileID string) (*GetFollowingResponse, error) {
-	url := fmt.Sprintf("%s/profiles/%s/following?apiKey=%s", c.tapestryApiBaseUrl, profileID, c.apiKey)
+// GetFollowers returns one page of the profiles following profileID. TotalCount
+// is set when the API reports it. Use IterateFollowers to walk all of them.
+func (c *TapestryClient) GetFollowers(ctx context.Context, profileID string, opts ...PageOption) (*GetFollowersResponse, error) {
+	uri := c.endpoint(pageQuery(url.Values{}, opts), "profiles", profileID, "followers")
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+	var followersResp GetFollowersResponse
+	if err := c.doJSON(ctx, opGetFollowers, uri, nil, &followersResp); err != nil {
+		return nil, err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
-	}
-	defer resp.Body.Close()
+	return &followersResp, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+// GetFollowing returns one page of the profiles profileID follows. TotalCount
+// is set when the API reports it. Use IterateFollowing to walk all of them.
+func (c *TapestryClient) GetFollowing(ctx context.Context, profileID string, opts ...PageOption) (*GetFollowingResponse, error) {
+	uri := c.endpoint(pageQuery(url.Values{}, opts), "profiles", profileID, "following")
 
 	var followingResp GetFollowingResponse
-	if err := json.NewDecoder(resp.Body).Decode(&followingResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if err := c.doJSON(ctx, opGetFollowing, uri, nil, &followingResp); err != nil {
+		return nil, err
 	}
 
 	return &followingResp, nil
 }
 
-func (c *TapestryClient) GetFollowingWhoFollow(ctx context.Context, profileID string, requestorID string) (*GetFollowingWhoFollowResponse, error) {
-	url := fmt.Sprintf("%s/profiles/%s/following-who-follow?apiKey=%s&requestorId=%s",
-		c.tapestryApiBaseUrl, profileID, c.apiKey, requestorID)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+func (c *TapestryClient) GetFollowingWhoFollow(ctx context.Context, profileID string, requestorID string, opts ...PageOption) (*GetFollowingWhoFollowResponse, error) {
+	uri := c.endpoint(pageQuery(url.Values{"requestorId": {requestorID}}, opts), "profiles", profileID, "following-who-follow")
 
 	var followingWhoFollowResp GetFollowingWhoFollowResponse
-	if err := json.NewDecoder(resp.Body).Decode(&followingWhoFollowResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if err := c.doJSON(ctx, opGetFollowingWhoFollow, uri, nil, &followingWhoFollowResp); err != nil {
+		return nil, err
 	}
 
 	return &followingWhoFollowResp, nil
 }
 
+// GetSuggestedProfiles returns profiles related to the wallet address, which
+// is checked and normalized for the client's blockchain first. An invalid
+// address fails with a *ValidationError.
 func (c *TapestryClient) GetSuggestedProfiles(ctx context.Context, address string, ownAppOnly bool) (*GetSuggestedProfilesResponse, error) {
-	url := fmt.Sprintf("%s/profiles/suggested/%s?apiKey=%s&ownAppOnly=%t",
-		c.tapestryApiBaseUrl, address, c.apiKey, ownAppOnly)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	var v validator
+	address, err := v.walletAddress("walletAddress", c.blockchainFor(ctx), address)
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+		return nil, err
 	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+	if err := v.err(); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	uri := c.endpoint(url.Values{"ownAppOnly": {strconv.FormatBool(ownAppOnly)}}, "profiles", "suggested", address)
 
 	var rawResponse map[string]SuggestedProfileValue
-	if err := json.NewDecoder(resp.Body).Decode(&rawResponse); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if err := c.doJSON(ctx, opGetSuggestedProfiles, uri, nil, &rawResponse); err != nil {
+		return nil, err
 	}
 
 	return &GetSuggestedProfilesResponse{Profiles: rawResponse}, nil
@@ -0,0 +1,321 @@
+package tapestry
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Property is any of the key/value property types of contents, comments and
+// profiles.
+type Property interface {
+	ContentProperty | CommentProperty | ProfileProperty
+}
+
+// property has the same fields as every Property type, so it converts to
+// each of them.
+type property struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// EncodeProperties turns the exported fields of the struct v points to into
+// properties, for example to pass to FindOrCreateContent:
+//
+//	type Post struct {
+//		Title     string    `tapestry:"title,required"`
+//		Published time.Time `tapestry:"published,omitempty"`
+//		Tags      []string  `tapestry:"tags,omitempty"`
+//		Author    struct {
+//			Name string `tapestry:"name"`
+//		} `tapestry:"author"`
+//	}
+//	properties, err := tapestry.EncodeProperties[tapestry.ContentProperty](post)
+//
+// The key of a field is the name in its tapestry tag, or the field name, and
+// a tag of "-" skips the field. Strings, numbers and bools are written as
+// text, types implementing encoding.TextMarshaler such as time.Time through
+// it, and slices, arrays and maps as JSON. Nested structs are flattened into
+// dotted keys such as "author.name", and nil pointers are left out.
+//
+// The omitempty option leaves out zero values. The required option rejects
+// nil pointers and empty strings, slices and maps, but not zero numbers or
+// bools: every missing required field is listed in a *ValidationError.
+func EncodeProperties[P Property](v any) ([]P, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("tapestry: cannot encode properties from %T, want a struct", v)
+	}
+
+	var e propertyEncoder
+	if err := e.encodeStruct("", rv); err != nil {
+		return nil, err
+	}
+	if err := e.missing.err(); err != nil {
+		return nil, err
+	}
+
+	props := make([]P, len(e.props))
+	for i, p := range e.props {
+		props[i] = P(p)
+	}
+	return props, nil
+}
+
+// DecodeProperties sets the fields of the struct v points to from props, the
+// reverse of EncodeProperties. Keys without a field are ignored. Every missing
+// required field is listed in a *ValidationError.
+func DecodeProperties[P Property](props []P, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("tapestry: cannot decode properties into %T, want a pointer to a struct", v)
+	}
+
+	d := propertyDecoder{values: make(map[string]string, len(props))}
+	for _, p := range props {
+		p := property(p)
+		d.values[p.Key] = p.Value
+	}
+	if err := d.decodeStruct("", rv.Elem()); err != nil {
+		return err
+	}
+	return d.missing.err()
+}
+
+type propertyTag struct {
+	key                 string
+	omitempty, required bool
+}
+
+// parsePropertyTag returns the options of field f, and false if it is not
+// encoded.
+func parsePropertyTag(f reflect.StructField) (propertyTag, bool) {
+	if !f.IsExported() {
+		return propertyTag{}, false
+	}
+	tag := f.Tag.Get("tapestry")
+	if tag == "-" {
+		return propertyTag{}, false
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+	t := propertyTag{key: name}
+	if t.key == "" {
+		t.key = f.Name
+	}
+	for _, option := range strings.Split(options, ",") {
+		switch option {
+		case "omitempty":
+			t.omitempty = true
+		case "required":
+			t.required = true
+		}
+	}
+	return t, true
+}
+
+// nestedStruct reports whether values of t are flattened into dotted keys
+// rather than encoded as one value.
+func nestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !t.Implements(textMarshalerType) && !reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+type propertyEncoder struct {
+	props   []property
+	missing validator
+}
+
+func (e *propertyEncoder) encodeStruct(prefix string, rv reflect.Value) error {
+	for i := 0; i < rv.NumField(); i++ {
+		tag, ok := parsePropertyTag(rv.Type().Field(i))
+		if !ok {
+			continue
+		}
+		key := prefix + tag.key
+		fv := rv.Field(i)
+
+		if tag.required && emptyProperty(fv) {
+			e.missing.add(key, "is required")
+			continue
+		}
+		if tag.omitempty && fv.IsZero() {
+			continue
+		}
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+
+		if nestedStruct(fv.Type()) {
+			if err := e.encodeStruct(key+".", fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, err := encodePropertyValue(fv)
+		if err != nil {
+			return fmt.Errorf("tapestry: property %q: %w", key, err)
+		}
+		e.props = append(e.props, property{Key: key, Value: value})
+	}
+	return nil
+}
+
+// emptyProperty reports whether a required field has no value to write. Zero
+// numbers and bools are values, as they are when decoding.
+func emptyProperty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return false
+}
+
+func encodePropertyValue(v reflect.Value) (string, error) {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+	if v.CanAddr() {
+		if m, ok := v.Addr().Interface().(encoding.TextMarshaler); ok {
+			text, err := m.MarshalText()
+			return string(text), err
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		data, err := json.Marshal(v.Interface())
+		return string(data), err
+	}
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
+
+type propertyDecoder struct {
+	values  map[string]string
+	missing validator
+}
+
+// hasPrefix reports whether any property key starts with prefix.
+func (d *propertyDecoder) hasPrefix(prefix string) bool {
+	for key := range d.values {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *propertyDecoder) decodeStruct(prefix string, rv reflect.Value) error {
+	for i := 0; i < rv.NumField(); i++ {
+		tag, ok := parsePropertyTag(rv.Type().Field(i))
+		if !ok {
+			continue
+		}
+		key := prefix + tag.key
+		fv := rv.Field(i)
+
+		if nestedStruct(fv.Type()) {
+			if !d.hasPrefix(key + ".") {
+				if tag.required {
+					d.missing.add(key, "is required")
+				}
+				continue
+			}
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if err := d.decodeStruct(key+".", fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, ok := d.values[key]
+		if !ok {
+			if tag.required {
+				d.missing.add(key, "is required")
+			}
+			continue
+		}
+		if err := decodePropertyValue(value, fv); err != nil {
+			return fmt.Errorf("tapestry: property %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func decodePropertyValue(value string, v reflect.Value) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return json.Unmarshal([]byte(value), v.Addr().Interface())
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
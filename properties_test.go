@@ -0,0 +1,154 @@
+package tapestry
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type propertiesAuthor struct {
+	Name  string `tapestry:"name,required"`
+	Karma *int   `tapestry:"karma,omitempty"`
+}
+
+type propertiesPost struct {
+	Title     string            `tapestry:"title,required"`
+	Views     int64             `tapestry:"views"`
+	Score     float64           `tapestry:"score,omitempty"`
+	Draft     bool              `tapestry:"draft"`
+	Published time.Time         `tapestry:"published,omitempty"`
+	Tags      []string          `tapestry:"tags,omitempty"`
+	Links     map[string]string `tapestry:"links,omitempty"`
+	Author    propertiesAuthor  `tapestry:"author"`
+	Editor    *propertiesAuthor `tapestry:"editor,omitempty"`
+	Rating    uint8
+	Secret    string `tapestry:"-"`
+	internal  string
+}
+
+func TestProperties_RoundTrip(t *testing.T) {
+	karma := 42
+	post := propertiesPost{
+		Title:     "Hello",
+		Views:     1200,
+		Score:     0.5,
+		Published: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		Tags:      []string{"go", "web3"},
+		Author:    propertiesAuthor{Name: "alice", Karma: &karma},
+		Editor:    &propertiesAuthor{Name: "bob"},
+		Rating:    5,
+		Secret:    "s",
+		internal:  "i",
+	}
+
+	props, err := EncodeProperties[ContentProperty](&post)
+	if err != nil {
+		t.Fatalf("EncodeProperties() error = %v", err)
+	}
+	want := []ContentProperty{
+		{Key: "title", Value: "Hello"},
+		{Key: "views", Value: "1200"},
+		{Key: "score", Value: "0.5"},
+		{Key: "draft", Value: "false"},
+		{Key: "published", Value: "2024-05-01T12:30:00Z"},
+		{Key: "tags", Value: `["go","web3"]`},
+		{Key: "author.name", Value: "alice"},
+		{Key: "author.karma", Value: "42"},
+		{Key: "editor.name", Value: "bob"},
+		{Key: "Rating", Value: "5"},
+	}
+	if !reflect.DeepEqual(props, want) {
+		t.Fatalf("EncodeProperties() =\n%v\nwant\n%v", props, want)
+	}
+	if err := (FindOrCreateContentRequest{ProfileID: "p1", Properties: props}).Validate(); err != nil {
+		t.Errorf("encoded keys do not pass validation: %v", err)
+	}
+
+	var decoded propertiesPost
+	if err := DecodeProperties(props, &decoded); err != nil {
+		t.Fatalf("DecodeProperties() error = %v", err)
+	}
+	post.Secret, post.internal = "", ""
+	if !reflect.DeepEqual(decoded, post) {
+		t.Errorf("DecodeProperties() =\n%+v\nwant\n%+v", decoded, post)
+	}
+}
+
+func TestProperties_Types(t *testing.T) {
+	comment, err := EncodeProperties[CommentProperty](struct {
+		Mood string `tapestry:"mood"`
+	}{"happy"})
+	if err != nil || !reflect.DeepEqual(comment, []CommentProperty{{Key: "mood", Value: "happy"}}) {
+		t.Errorf("EncodeProperties[CommentProperty]() = %v, %v", comment, err)
+	}
+
+	var profile struct {
+		Twitter string `tapestry:"twitter"`
+	}
+	if err := DecodeProperties([]ProfileProperty{{Key: "twitter", Value: "@alice"}, {Key: "unknown", Value: "x"}}, &profile); err != nil || profile.Twitter != "@alice" {
+		t.Errorf("DecodeProperties([]ProfileProperty) = %+v, %v", profile, err)
+	}
+}
+
+func TestProperties_Required(t *testing.T) {
+	_, err := EncodeProperties[ContentProperty](propertiesPost{Editor: &propertiesAuthor{}})
+	if got := fieldNames(t, err); !reflect.DeepEqual(got, []string{"title", "author.name", "editor.name"}) {
+		t.Errorf("EncodeProperties() missing fields = %v", got)
+	}
+
+	var post propertiesPost
+	err = DecodeProperties([]ContentProperty{{Key: "author.karma", Value: "1"}}, &post)
+	if got := fieldNames(t, err); !reflect.DeepEqual(got, []string{"title", "author.name"}) {
+		t.Errorf("DecodeProperties() missing fields = %v", got)
+	}
+	if post.Editor != nil {
+		t.Errorf("DecodeProperties() allocated an editor without any of its keys")
+	}
+}
+
+func TestProperties_RequiredZeroValues(t *testing.T) {
+	type counts struct {
+		Views  int     `tapestry:"views,required"`
+		Score  float64 `tapestry:"score,required"`
+		Pinned bool    `tapestry:"pinned,required"`
+	}
+
+	props, err := EncodeProperties[ContentProperty](counts{})
+	if err != nil {
+		t.Fatalf("EncodeProperties() error = %v", err)
+	}
+	want := []ContentProperty{{Key: "views", Value: "0"}, {Key: "score", Value: "0"}, {Key: "pinned", Value: "false"}}
+	if !reflect.DeepEqual(props, want) {
+		t.Errorf("EncodeProperties() = %v, want %v", props, want)
+	}
+
+	decoded := counts{Views: 1, Score: 1, Pinned: true}
+	if err := DecodeProperties(props, &decoded); err != nil {
+		t.Fatalf("DecodeProperties() error = %v", err)
+	}
+	if decoded != (counts{}) {
+		t.Errorf("DecodeProperties() = %+v, want zero values", decoded)
+	}
+}
+
+func TestProperties_Errors(t *testing.T) {
+	if _, err := EncodeProperties[ContentProperty]("title"); err == nil {
+		t.Error("EncodeProperties() of a string succeeded")
+	}
+	if _, err := EncodeProperties[ContentProperty](struct{ C chan int }{}); err == nil {
+		t.Error("EncodeProperties() of a channel succeeded")
+	}
+
+	var post propertiesPost
+	if err := DecodeProperties([]ContentProperty{}, post); err == nil {
+		t.Error("DecodeProperties() into a non-pointer succeeded")
+	}
+
+	bad := []ContentProperty{{Key: "title", Value: "t"}, {Key: "author.name", Value: "a"}, {Key: "views", Value: "many"}}
+	err := DecodeProperties(bad, &post)
+	if err == nil || errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), `"views"`) {
+		t.Errorf("DecodeProperties() error = %v, want a parse error naming the key", err)
+	}
+}
@@ -0,0 +1,161 @@
+package tapestry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token bucket budget. Rate is the number of requests allowed
+// per second and Burst the number that may be sent at once. A Rate of zero or
+// less means unlimited.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimiterConfig holds separate budgets for read and write endpoints.
+type RateLimiterConfig struct {
+	Read  RateLimit
+	Write RateLimit
+	// FailFast makes calls return a *RateLimitError instead of waiting for a
+	// token to become available.
+	FailFast bool
+}
+
+// RateLimitError is returned when a call is rejected by the client-side rate
+// limiter. It matches ErrRateLimited through errors.Is.
+type RateLimitError struct {
+	// Write reports whether the write budget was exhausted.
+	Write bool
+	// RetryAfter is how long until a token becomes available.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	class := "read"
+	if e.Write {
+		class = "write"
+	}
+	return fmt.Sprintf("client rate limit exceeded for %s requests, retry after %v", class, e.RetryAfter)
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// RateLimiter throttles requests before they leave the client. It is safe for
+// concurrent use and may be shared by several clients to enforce a common
+// quota.
+type RateLimiter struct {
+	read     *tokenBucket
+	write    *tokenBucket
+	failFast bool
+}
+
+func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
+	return &RateLimiter{
+		read:     newTokenBucket(config.Read),
+		write:    newTokenBucket(config.Write),
+		failFast: config.FailFast,
+	}
+}
+
+// SetRateLimiter makes every call made by c draw from limiter. Retries draw a
+// token as well. It must be called before the client is used concurrently.
+func (c *TapestryClient) SetRateLimiter(limiter *RateLimiter) {
+	c.rateLimiter = limiter
+}
+
+// wait blocks until a token for op is available, ctx is done, or the wait
+// would outlive the ctx deadline. In fail fast mode it never blocks. It
+// returns how long the call was held back.
+func (l *RateLimiter) wait(ctx context.Context, op operation) (time.Duration, error) {
+	bucket := l.read
+	if op.write {
+		bucket = l.write
+	}
+	if bucket == nil {
+		return 0, nil
+	}
+
+	delay, ok := bucket.reserve(time.Now(), !l.failFast)
+	if !ok {
+		return 0, &RateLimitError{Write: op.write, RetryAfter: delay}
+	}
+	if delay <= 0 {
+		return 0, nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		bucket.cancel()
+		return 0, &RateLimitError{Write: op.write, RetryAfter: delay}
+	}
+	start := time.Now()
+	if err := sleep(ctx, delay); err != nil {
+		bucket.cancel()
+		return time.Since(start), err
+	}
+
+	return time.Since(start), nil
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	if limit.Rate <= 0 {
+		return nil
+	}
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long the caller has to wait before
+// using it. When wait is false and no token is available right away, nothing
+// is taken and ok is false.
+func (b *tokenBucket) reserve(now time.Time, wait bool) (delay time.Duration, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+
+	delay = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	if !wait {
+		return delay, false
+	}
+	// go into debt so concurrent callers queue up behind this one
+	b.tokens--
+	return delay, true
+}
+
+// cancel returns a token taken by a reservation that was not used.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
@@ -0,0 +1,119 @@
+package tapestry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket_Reserve(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{Rate: 10, Burst: 2})
+	now := bucket.last
+
+	for i := 0; i < 2; i++ {
+		if delay, ok := bucket.reserve(now, false); !ok || delay != 0 {
+			t.Fatalf("reserve() #%d = %v, %v; want immediate token", i+1, delay, ok)
+		}
+	}
+
+	delay, ok := bucket.reserve(now, false)
+	if ok || delay != 100*time.Millisecond {
+		t.Fatalf("reserve() on empty bucket = %v, %v; want 100ms, false", delay, ok)
+	}
+
+	delay, ok = bucket.reserve(now, true)
+	if !ok || delay != 100*time.Millisecond {
+		t.Fatalf("waiting reserve() = %v, %v; want 100ms, true", delay, ok)
+	}
+	delay, _ = bucket.reserve(now, true)
+	if delay != 200*time.Millisecond {
+		t.Fatalf("second waiting reserve() = %v, want 200ms", delay)
+	}
+
+	if delay, ok := bucket.reserve(now.Add(time.Second), false); !ok || delay != 0 {
+		t.Fatalf("reserve() after refill = %v, %v; want immediate token", delay, ok)
+	}
+}
+
+func TestRateLimiter_FailFast(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewTapestryClientWithHTTPClient("key", server.URL, ExecutionFastUnconfirmed, "SOLANA", server.Client())
+	client.SetRateLimiter(NewRateLimiter(RateLimiterConfig{
+		Read:     RateLimit{Rate: 0.001, Burst: 1},
+		Write:    RateLimit{Rate: 0.001, Burst: 1},
+		FailFast: true,
+	}))
+	ctx := context.Background()
+
+	if _, err := client.GetFollowers(ctx, "p1"); err != nil {
+		t.Fatalf("first GetFollowers() error = %v", err)
+	}
+	_, err := client.GetFollowers(ctx, "p1")
+	var limitErr *RateLimitError
+	if !errors.As(err, &limitErr) || !errors.Is(err, ErrRateLimited) || limitErr.Write {
+		t.Fatalf("second GetFollowers() error = %v, want read *RateLimitError", err)
+	}
+
+	// writes have their own budget
+	if err := client.AddFollower(ctx, "a", "b"); err != nil {
+		t.Fatalf("AddFollower() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("server saw %d calls, want 2", calls)
+	}
+}
+
+func TestRateLimiter_BlocksUntilTokenAvailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewTapestryClientWithHTTPClient("key", server.URL, ExecutionFastUnconfirmed, "SOLANA", server.Client())
+	client.SetRateLimiter(NewRateLimiter(RateLimiterConfig{Read: RateLimit{Rate: 50, Burst: 1}}))
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetFollowing(context.Background(), "p1"); err != nil {
+				t.Errorf("GetFollowing() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// one token up front, four more at 20ms intervals
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("5 calls at 50/s finished in %v, want at least 70ms", elapsed)
+	}
+}
+
+func TestRateLimiter_RespectsContextDeadline(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{Write: RateLimit{Rate: 0.5, Burst: 1}})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := limiter.wait(ctx, opCreateComment); err != nil {
+		t.Fatalf("first wait() error = %v", err)
+	}
+	if _, err := limiter.wait(ctx, opCreateComment); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("wait() beyond deadline error = %v, want ErrRateLimited", err)
+	}
+	if tokens := limiter.write.tokens; tokens < -0.01 {
+		t.Errorf("rejected wait left bucket at %v tokens, want the token returned", tokens)
+	}
+}
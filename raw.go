@@ -0,0 +1,68 @@
+package tapestry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// rawOperation describes a call made through Do. All of them share one name
+// so that metrics and traces keep a bounded set of labels.
+func rawOperation(method string) operation {
+	op := operation{name: "raw", fn: "Do", method: method}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		op.idempotent = true
+	case http.MethodPut, http.MethodDelete:
+		op.idempotent = true
+		op.write = true
+	default:
+		op.write = true
+	}
+	return op
+}
+
+// Do sends a request to an endpoint the client does not wrap yet. It goes
+// through the same base URL, authentication, interceptors, rate limits,
+// retries and error handling as every other method, under the operation name
+// "raw".
+//
+// path is relative to the base URL, for example "/profiles/p1/followers", and
+// may carry its own query, which query adds to. Paths with "." or ".."
+// segments are rejected, so a call cannot leave the base URL. A non-nil body is sent as
+// JSON, and a successful JSON response is decoded into out unless it is nil.
+// GET, HEAD, OPTIONS, PUT and DELETE requests are retried per the client's
+// RetryPolicy; other methods only with RetryNonIdempotent.
+func (c *TapestryClient) Do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	method = strings.ToUpper(method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	rel, err := url.Parse(path)
+	if err != nil {
+		return fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	if rel.IsAbs() || rel.Host != "" {
+		return fmt.Errorf("invalid path %q: must be relative to the base URL", path)
+	}
+	// dot segments, escaped or not, could climb out of the base URL
+	for _, segment := range strings.Split(rel.Path, "/") {
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("invalid path %q: must not contain %q segments", path, segment)
+		}
+	}
+
+	uri := c.tapestryApiBaseUrl + "/" + strings.TrimPrefix(rel.EscapedPath(), "/")
+	params := rel.Query()
+	for key, values := range query {
+		params[key] = append(params[key], values...)
+	}
+	if len(params) > 0 {
+		uri += "?" + params.Encode()
+	}
+
+	return c.doJSON(ctx, rawOperation(method), uri, body, out)
+}
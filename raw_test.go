@@ -0,0 +1,112 @@
+package tapestry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDo(t *testing.T) {
+	var got *http.Request
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody = nil
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		switch r.URL.Path {
+		case "/api/v1/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/api/v1/empty":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"n1"}`))
+		}
+	}))
+	defer server.Close()
+
+	var intercepted []*Call
+	client, _ := New(testAPIKey, WithBaseURL(server.URL+"/api/v1/"), WithHTTPClient(server.Client()),
+		WithInterceptors(func(ctx context.Context, call *Call, next Invoker) error {
+			intercepted = append(intercepted, call)
+			return next(ctx, call)
+		}))
+	ctx := context.Background()
+
+	var out struct {
+		ID string `json:"id"`
+	}
+	err := client.Do(ctx, "post", "notifications/send?channel=push", url.Values{"profileId": {"p 1"}}, map[string]string{"text": "hi"}, &out)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if out.ID != "n1" {
+		t.Errorf("decoded %+v, want id n1", out)
+	}
+	if got.Method != http.MethodPost || got.URL.Path != "/api/v1/notifications/send" {
+		t.Errorf("request = %s %s", got.Method, got.URL.Path)
+	}
+	if q := got.URL.Query(); q.Get("channel") != "push" || q.Get("profileId") != "p 1" {
+		t.Errorf("query = %v", q)
+	}
+	if got.Header.Get(apiKeyHeader) != testAPIKey || gotBody["text"] != "hi" {
+		t.Errorf("request is missing the API key or body: %v %v", got.Header, gotBody)
+	}
+	if len(intercepted) != 1 || intercepted[0].Operation != "raw" || intercepted[0].ClientMethod != "Do" {
+		t.Errorf("interceptors saw %+v", intercepted)
+	}
+
+	if err := client.Do(ctx, http.MethodDelete, "/empty", nil, nil, &out); err != nil {
+		t.Errorf("Do() on a 204 error = %v", err)
+	}
+
+	err = client.Do(ctx, http.MethodGet, "/missing", nil, nil, nil)
+	var apiErr *APIError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &apiErr) || apiErr.Endpoint != "/api/v1/missing" {
+		t.Errorf("Do() error = %v, want an APIError matching ErrNotFound", err)
+	}
+}
+
+func TestDo_RejectsAbsoluteURLs(t *testing.T) {
+	client, _ := New(testAPIKey, WithHTTPClient(failingDoer{}))
+
+	for _, path := range []string{"https://evil.example/steal", "//evil.example/steal"} {
+		if err := client.Do(context.Background(), http.MethodGet, path, nil, nil, nil); err == nil {
+			t.Errorf("Do(%q) sent the API key to another host", path)
+		}
+	}
+}
+
+func TestDo_RejectsDotSegments(t *testing.T) {
+	client, _ := New(testAPIKey, WithHTTPClient(failingDoer{}))
+
+	for _, path := range []string{"../../x", "/profiles/../../x", "./profiles", "profiles/%2E%2E/%2e%2E/x", ".."} {
+		if err := client.Do(context.Background(), http.MethodGet, path, nil, nil, nil); err == nil || !strings.Contains(err.Error(), "segments") {
+			t.Errorf("Do(%q) error = %v, want the dot segment rejected", path, err)
+		}
+	}
+}
+
+func TestRawOperation(t *testing.T) {
+	tests := []struct {
+		method            string
+		idempotent, write bool
+	}{
+		{http.MethodGet, true, false},
+		{http.MethodPut, true, true},
+		{http.MethodDelete, true, true},
+		{http.MethodPost, false, true},
+		{http.MethodPatch, false, true},
+	}
+	for _, tt := range tests {
+		op := rawOperation(tt.method)
+		if op.idempotent != tt.idempotent || op.write != tt.write {
+			t.Errorf("rawOperation(%s) = idempotent %v, write %v", tt.method, op.idempotent, op.write)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package tapestry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. Responses with status
+// 429, 502, 503 and 504 and transport errors are retried with jittered
+// exponential backoff. The zero value disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry. It doubles on every
+	// following attempt up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxRetryAfter is the longest Retry-After the client waits for. A
+	// response asking for a longer wait is returned instead of retried. Zero
+	// uses MaxBackoff, or one minute if that is zero too.
+	MaxRetryAfter time.Duration
+	// RetryNonIdempotent allows replaying calls that are not safe to repeat,
+	// such as CreateComment, CreateLike and AddFollower.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns a policy suitable for most callers.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		MinBackoff:  200 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+	}
+}
+
+// SetRetryPolicy configures retries for every call made by c. It must be
+// called before the client is used concurrently.
+func (c *TapestryClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retryPolicy = policy
+}
+
+func (p RetryPolicy) attempts(op operation) int {
+	if p.MaxAttempts < 2 || !(op.idempotent || p.RetryNonIdempotent) {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// maxRetryAfter returns the longest Retry-After worth waiting for.
+func (p RetryPolicy) maxRetryAfter() time.Duration {
+	switch {
+	case p.MaxRetryAfter > 0:
+		return p.MaxRetryAfter
+	case p.MaxBackoff > 0:
+		return p.MaxBackoff
+	}
+	return time.Minute
+}
+
+// backoff returns the delay before retry number attempt (starting at 1).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MinBackoff
+	if delay <= 0 {
+		delay = 100 * time.Millisecond
+	}
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+
+	// equal jitter: wait between half and the full delay
+	half := delay / 2
+	return half + jitter(delay-half)
+}
+
+var (
+	jitterMu  sync.Mutex
+	jitterRnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+func jitter(n time.Duration) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return time.Duration(jitterRnd.Int63n(int64(n) + 1))
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header, which holds either a number of
+// seconds or an HTTP date.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// send executes req for op, retrying according to the client's RetryPolicy.
+// Every attempt first waits for the rate limiter, if one is set, and hands
+// hooks a fresh copy of req without the API key.
+// The request body must be replayable through req.GetBody, which
+// http.NewRequest sets up for in-memory bodies.
+func (c *TapestryClient) send(op operation, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	attempts := c.retryPolicy.attempts(op)
+	// every retry starts from the request as it was before hooks ran
+	base := req.Clone(ctx)
+
+	for attempt := 1; ; attempt++ {
+		if c.rateLimiter != nil {
+			waited, err := c.rateLimiter.wait(ctx, op)
+			if waited > 0 && c.metrics != nil {
+				c.metrics.ObserveRateLimitWait(op.name, waited)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		c.hooks.request(ctx, op, req)
+		start := time.Now()
+		resp, err := c.doAuthorized(req)
+		if resp != nil {
+			resp.Request = req
+		}
+		c.hooks.response(ctx, op, req, resp, err, time.Since(start))
+		if attempt >= attempts || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		wait := c.retryPolicy.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp, time.Now()); ok {
+				if after > c.retryPolicy.maxRetryAfter() {
+					// the server asks for longer than the policy allows
+					return resp, err
+				}
+				wait = after
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			// waiting would outlive the caller, report the last failure
+			return resp, err
+		}
+		if c.logger != nil {
+			attrs := []any{"operation", op.name, "attempt", attempt, "wait", wait}
+			if resp != nil {
+				attrs = append(attrs, "status", resp.StatusCode)
+			} else {
+				attrs = append(attrs, "error", err)
+			}
+			c.logger.DebugContext(ctx, "retrying tapestry request", attrs...)
+		}
+		c.hooks.retry(ctx, op, attempt, wait)
+		if c.metrics != nil {
+			c.metrics.IncRetry(op.name)
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+			resp.Body.Close()
+		}
+
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+
+		if req, err = rewind(base); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// the caller gave up, retrying cannot help
+		return ctx.Err() == nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return isRetryableStatus(resp.StatusCode)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rewind returns a copy of req with a fresh body for the next attempt.
+func rewind(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.Body == nil || req.GetBody == nil {
+		return next, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	next.Body = body
+	return next, nil
+}
@@ -0,0 +1,204 @@
+package tapestry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newRetryTestClient(t *testing.T, handler http.HandlerFunc, policy RetryPolicy) (*TapestryClient, func()) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	client := NewTapestryClientWithHTTPClient("key", server.URL, ExecutionFastUnconfirmed, "SOLANA", server.Client())
+	client.SetRetryPolicy(policy)
+	return &client, server.Close
+}
+
+func TestRetry_RetriesTransientStatuses(t *testing.T) {
+	var calls int32
+	var bodies []string
+	client, closeServer := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = w.Write([]byte(`{"content":{"id":"c1"}}`))
+		}
+	}, RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+	defer closeServer()
+
+	if _, err := client.FindOrCreateContent(context.Background(), "p1", "c1", nil); err != nil {
+		t.Fatalf("FindOrCreateContent() error = %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("server saw %d calls, want 3", calls)
+	}
+	for i, body := range bodies {
+		if body != bodies[0] || body == "" {
+			t.Errorf("attempt %d sent body %q, want %q", i+1, body, bodies[0])
+		}
+	}
+}
+
+func TestRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	client, closeServer := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}, RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond})
+	defer closeServer()
+
+	_, err := client.GetProfileByID(context.Background(), "p1")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("GetProfileByID() error = %v, want 502 APIError", err)
+	}
+	if calls != 3 {
+		t.Errorf("server saw %d calls, want 3", calls)
+	}
+}
+
+func TestRetry_NonIdempotentRequiresOptIn(t *testing.T) {
+	for _, optIn := range []bool{false, true} {
+		var calls int32
+		client, closeServer := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{}`))
+		}, RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, RetryNonIdempotent: optIn})
+
+		err := client.AddFollower(context.Background(), "a", "b")
+		closeServer()
+
+		if optIn && (err != nil || calls != 2) {
+			t.Errorf("with opt-in: AddFollower() error = %v after %d calls, want success after 2", err, calls)
+		}
+		if !optIn && (err == nil || calls != 1) {
+			t.Errorf("without opt-in: AddFollower() error = %v after %d calls, want failure after 1", err, calls)
+		}
+	}
+}
+
+func TestRetry_RespectsContextDeadline(t *testing.T) {
+	var calls int32
+	client, closeServer := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, DefaultRetryPolicy())
+	defer closeServer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetContents(ctx)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("GetContents() error = %v, want ErrRateLimited", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("GetContents() waited %v despite a Retry-After beyond the deadline", elapsed)
+	}
+	if calls != 1 {
+		t.Errorf("server saw %d calls, want 1", calls)
+	}
+}
+
+func TestRetry_GivesUpOnLongRetryAfter(t *testing.T) {
+	var calls int32
+	policy := DefaultRetryPolicy()
+	policy.MaxRetryAfter = 2 * time.Second
+	client, closeServer := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, policy)
+	defer closeServer()
+
+	start := time.Now()
+	_, err := client.GetContents(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("GetContents() error = %v, want the 503", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("GetContents() waited %v for a Retry-After beyond MaxRetryAfter", elapsed)
+	}
+	if calls != 1 {
+		t.Errorf("server saw %d calls, want 1", calls)
+	}
+}
+
+func TestRetryPolicy_MaxRetryAfter(t *testing.T) {
+	tests := []struct {
+		policy RetryPolicy
+		want   time.Duration
+	}{
+		{RetryPolicy{MaxRetryAfter: 30 * time.Second, MaxBackoff: time.Second}, 30 * time.Second},
+		{RetryPolicy{MaxBackoff: 5 * time.Second}, 5 * time.Second},
+		{RetryPolicy{}, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.maxRetryAfter(); got != tt.want {
+			t.Errorf("%+v.maxRetryAfter() = %v, want %v", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{5, time.Second},
+		{20, time.Second},
+	}
+
+	for _, tt := range tests {
+		got := policy.backoff(tt.attempt)
+		if got < tt.max/2 || got > tt.max {
+			t.Errorf("backoff(%d) = %v, want within [%v, %v]", tt.attempt, got, tt.max/2, tt.max)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{now.Add(5 * time.Second).Format(http.TimeFormat), 5 * time.Second, true},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.header != "" {
+			resp.Header.Set("Retry-After", tt.header)
+		}
+		got, ok := retryAfter(resp, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
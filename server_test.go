@@ -0,0 +1,104 @@
+package tapestry
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// recordedRequest is a request as a recordingServer received it.
+type recordedRequest struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   []byte
+}
+
+// decode unmarshals the JSON body into v.
+func (r recordedRequest) decode(t testing.TB, v any) {
+	t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		t.Fatalf("%s %s: invalid JSON body %q: %v", r.Method, r.URL, r.Body, err)
+	}
+}
+
+// recordingServer is a test server that records every request before handing
+// it to its handler, which can still read the body.
+type recordingServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	recorded []recordedRequest
+}
+
+// newRecordingServer starts a server answered by handler, or with {} when
+// handler is nil. It is closed when the test ends.
+func newRecordingServer(t testing.TB, handler http.HandlerFunc) *recordingServer {
+	if handler == nil {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}
+
+	s := &recordingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		s.mu.Lock()
+		s.recorded = append(s.recorded, recordedRequest{Method: r.Method, URL: r.URL, Header: r.Header.Clone(), Body: body})
+		s.mu.Unlock()
+
+		handler(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// requests returns the requests received so far, in order.
+func (s *recordingServer) requests() []recordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]recordedRequest(nil), s.recorded...)
+}
+
+func (s *recordingServer) last() recordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.recorded[len(s.recorded)-1]
+}
+
+func (s *recordingServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.recorded)
+}
+
+// countOf returns the number of requests with method for path.
+func (s *recordingServer) countOf(method, path string) int {
+	n := 0
+	for _, req := range s.requests() {
+		if req.Method == method && req.URL.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+// batchIDs returns the IDs of every batch read received, in order.
+func (s *recordingServer) batchIDs(t testing.TB) [][]string {
+	t.Helper()
+	var batches [][]string
+	for _, req := range s.requests() {
+		if req.Method == http.MethodPost && req.URL.Path == "/contents/batch/read" {
+			var ids []string
+			req.decode(t, &ids)
+			batches = append(batches, ids)
+		}
+	}
+	return batches
+}
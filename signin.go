@@ -0,0 +1,370 @@
+package tapestry
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	signInHeaderSuffix = " wants you to sign in with your Solana account:"
+	signInVersion      = "1"
+	signInResources    = "Resources:"
+
+	// DefaultSignInMaxAge is how long after it was issued a sign-in message is
+	// accepted when the verifier sets no MaxAge.
+	DefaultSignInMaxAge = 10 * time.Minute
+)
+
+// SignInMessage is a Sign-In With Solana message: the text a wallet signs to
+// prove it owns its address. String renders it in the standard format and
+// ParseSignInMessage reads it back.
+type SignInMessage struct {
+	// Domain is the host asking for the sign-in, for example "app.example.com".
+	Domain string
+	// Address is the base58 encoded Solana wallet signing in.
+	Address string
+	// Statement is an optional line shown to the user.
+	Statement string
+	// URI and ChainID are optional, for example "https://app.example.com" and
+	// "mainnet".
+	URI     string
+	ChainID string
+	Nonce   string
+	// IssuedAt is when the message was created. ExpirationTime and
+	// NotBefore, if not zero, are when it stops and starts being accepted.
+	IssuedAt       time.Time
+	ExpirationTime time.Time
+	NotBefore      time.Time
+	// RequestID and Resources are optional, for example "req-1" and
+	// []string{"https://app.example.com/terms"}.
+	RequestID string
+	Resources []string
+}
+
+// NewSignInMessage returns a message for address on domain with a fresh
+// nonce, issued now and expiring after ttl. A ttl of 0 or less sets no
+// expiration time.
+func NewSignInMessage(domain, address string, ttl time.Duration) (*SignInMessage, error) {
+	nonce, err := NewNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	msg := &SignInMessage{Domain: domain, Address: address, Nonce: nonce, IssuedAt: now}
+	if ttl > 0 {
+		msg.ExpirationTime = now.Add(ttl)
+	}
+	return msg, nil
+}
+
+// NewNonce returns 16 random bytes, hex encoded.
+func NewNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating nonce: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// String renders m as the text the wallet signs.
+func (m *SignInMessage) String() string {
+	var b strings.Builder
+	b.WriteString(m.Domain + signInHeaderSuffix + "\n")
+	b.WriteString(m.Address + "\n")
+	if m.Statement != "" {
+		b.WriteString("\n" + m.Statement + "\n")
+	}
+	b.WriteString("\n")
+
+	if m.URI != "" {
+		b.WriteString("URI: " + m.URI + "\n")
+	}
+	b.WriteString("Version: " + signInVersion + "\n")
+	if m.ChainID != "" {
+		b.WriteString("Chain ID: " + m.ChainID + "\n")
+	}
+	b.WriteString("Nonce: " + m.Nonce + "\n")
+	b.WriteString("Issued At: " + m.IssuedAt.UTC().Format(time.RFC3339) + "\n")
+	if !m.ExpirationTime.IsZero() {
+		b.WriteString("Expiration Time: " + m.ExpirationTime.UTC().Format(time.RFC3339) + "\n")
+	}
+	if !m.NotBefore.IsZero() {
+		b.WriteString("Not Before: " + m.NotBefore.UTC().Format(time.RFC3339) + "\n")
+	}
+	if m.RequestID != "" {
+		b.WriteString("Request ID: " + m.RequestID + "\n")
+	}
+	if len(m.Resources) > 0 {
+		b.WriteString(signInResources + "\n")
+		for _, resource := range m.Resources {
+			b.WriteString("- " + resource + "\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// ParseSignInMessage reads a message in the format written by
+// SignInMessage.String.
+func ParseSignInMessage(text string) (*SignInMessage, error) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if len(lines) < 3 || !strings.HasSuffix(lines[0], signInHeaderSuffix) {
+		return nil, &SignInError{Reason: "not a sign-in message"}
+	}
+
+	msg := &SignInMessage{
+		Domain:  strings.TrimSuffix(lines[0], signInHeaderSuffix),
+		Address: lines[1],
+	}
+
+	var statement []string
+	fields, resources := false, false
+	for _, line := range lines[2:] {
+		if resources {
+			resource, ok := strings.CutPrefix(line, "- ")
+			if !ok {
+				return nil, &SignInError{Reason: fmt.Sprintf("unexpected line %q", line)}
+			}
+			msg.Resources = append(msg.Resources, resource)
+			continue
+		}
+		if line == signInResources {
+			fields, resources = true, true
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok || !signInField(key) {
+			if fields && line != "" {
+				return nil, &SignInError{Reason: fmt.Sprintf("unexpected line %q", line)}
+			}
+			if line != "" {
+				statement = append(statement, line)
+			}
+			continue
+		}
+		fields = true
+
+		var err error
+		switch key {
+		case "URI":
+			msg.URI = value
+		case "Version":
+			if value != signInVersion {
+				return nil, &SignInError{Reason: fmt.Sprintf("unsupported version %q", value)}
+			}
+		case "Chain ID":
+			msg.ChainID = value
+		case "Nonce":
+			msg.Nonce = value
+		case "Issued At":
+			msg.IssuedAt, err = time.Parse(time.RFC3339, value)
+		case "Expiration Time":
+			msg.ExpirationTime, err = time.Parse(time.RFC3339, value)
+		case "Not Before":
+			msg.NotBefore, err = time.Parse(time.RFC3339, value)
+		case "Request ID":
+			msg.RequestID = value
+		}
+		if err != nil {
+			return nil, &SignInError{Reason: fmt.Sprintf("invalid %s: %v", key, err)}
+		}
+	}
+	msg.Statement = strings.Join(statement, "\n")
+
+	if msg.Domain == "" || msg.Address == "" || msg.Nonce == "" || msg.IssuedAt.IsZero() {
+		return nil, &SignInError{Reason: "missing domain, address, nonce or issue time"}
+	}
+	return msg, nil
+}
+
+func signInField(key string) bool {
+	switch key {
+	case "URI", "Version", "Chain ID", "Nonce", "Issued At", "Expiration Time", "Not Before", "Request ID":
+		return true
+	}
+	return false
+}
+
+// SignInError reports a sign-in proof that was rejected. It matches
+// ErrUnauthorized.
+type SignInError struct {
+	Reason string
+}
+
+func (e *SignInError) Error() string {
+	return "tapestry: sign-in rejected: " + e.Reason
+}
+
+func (e *SignInError) Is(target error) bool {
+	return target == ErrUnauthorized
+}
+
+// NonceStore remembers the nonces of accepted sign-ins so that each message
+// is accepted only once. Implementations must be safe for concurrent use;
+// back it with a shared store when several processes verify sign-ins.
+type NonceStore interface {
+	// Use marks nonce as used until expires and reports whether it was
+	// unused before.
+	Use(nonce string, expires time.Time) bool
+}
+
+// MemoryNonceStore is an in-memory NonceStore. It forgets nonces once they
+// have expired.
+type MemoryNonceStore struct {
+	now func() time.Time
+
+	mu   sync.Mutex
+	used map[string]time.Time
+}
+
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{now: time.Now, used: make(map[string]time.Time)}
+}
+
+func (s *MemoryNonceStore) Use(nonce string, expires time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for n, exp := range s.used {
+		if !exp.After(now) {
+			delete(s.used, n)
+		}
+	}
+
+	if _, ok := s.used[nonce]; ok {
+		return false
+	}
+	s.used[nonce] = expires
+	return true
+}
+
+// SignInVerifier checks Sign-In With Solana proofs for one domain.
+type SignInVerifier struct {
+	domain string
+	nonces NonceStore
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+// NewSignInVerifier returns a verifier that accepts messages for domain
+// issued at most maxAge ago, and each nonce once as recorded in nonces. A nil
+// nonces uses a MemoryNonceStore, and a maxAge of 0 or less
+// DefaultSignInMaxAge.
+func NewSignInVerifier(domain string, nonces NonceStore, maxAge time.Duration) *SignInVerifier {
+	if nonces == nil {
+		nonces = NewMemoryNonceStore()
+	}
+	if maxAge <= 0 {
+		maxAge = DefaultSignInMaxAge
+	}
+	return &SignInVerifier{domain: domain, nonces: nonces, maxAge: maxAge, now: time.Now}
+}
+
+// Verify checks that signature is the ed25519 signature of message by the
+// wallet it names, that message is meant for the verifier's domain, that it
+// is within its validity window, and that its nonce has not been used. It
+// returns the parsed message. Rejected proofs fail with a *SignInError.
+func (v *SignInVerifier) Verify(message string, signature []byte) (*SignInMessage, error) {
+	msg, expires, err := v.check(message, signature)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.use(msg, expires); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// check does everything Verify does but use the nonce, and returns when the
+// message expires.
+func (v *SignInVerifier) check(message string, signature []byte) (*SignInMessage, time.Time, error) {
+	msg, err := ParseSignInMessage(message)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if msg.Domain != v.domain {
+		return nil, time.Time{}, &SignInError{Reason: fmt.Sprintf("message is for domain %q", msg.Domain)}
+	}
+
+	now := v.now()
+	expires := msg.IssuedAt.Add(v.maxAge)
+	if !msg.ExpirationTime.IsZero() && msg.ExpirationTime.Before(expires) {
+		expires = msg.ExpirationTime
+	}
+	if now.Before(msg.IssuedAt.Add(-time.Minute)) {
+		return nil, time.Time{}, &SignInError{Reason: "message is issued in the future"}
+	}
+	if !msg.NotBefore.IsZero() && now.Before(msg.NotBefore) {
+		return nil, time.Time{}, &SignInError{Reason: "message is not valid yet"}
+	}
+	if !now.Before(expires) {
+		return nil, time.Time{}, &SignInError{Reason: "message has expired"}
+	}
+
+	address, err := BlockchainSolana.NormalizeAddress(msg.Address)
+	if err != nil {
+		return nil, time.Time{}, &SignInError{Reason: err.Error()}
+	}
+	key, _ := decodeBase58(address)
+	if len(signature) != ed25519.SignatureSize || !ed25519.Verify(ed25519.PublicKey(key), []byte(message), signature) {
+		return nil, time.Time{}, &SignInError{Reason: "invalid signature"}
+	}
+	return msg, expires, nil
+}
+
+// use records the nonce of an accepted message, failing if it was used
+// before.
+func (v *SignInVerifier) use(msg *SignInMessage, expires time.Time) error {
+	if !v.nonces.Use(msg.Nonce, expires) {
+		return &SignInError{Reason: "nonce has already been used"}
+	}
+	return nil
+}
+
+// SignInProof is what a wallet returns for a sign-in request: the message
+// text it signed and the raw 64-byte signature.
+type SignInProof struct {
+	Message   string
+	Signature []byte
+}
+
+// VerifiedFindOrCreateProfile calls FindOrCreateProfile only once verifier
+// accepts proof, with the wallet address taken from the signed message. It
+// fails with a *SignInError if the proof is rejected or params names another
+// wallet, and before verifying if the call is not on BlockchainSolana. The
+// nonce is only used up once params are valid, so a request rejected for its
+// params can be retried with the same proof.
+func (c *TapestryClient) VerifiedFindOrCreateProfile(ctx context.Context, verifier *SignInVerifier, proof SignInProof, params FindOrCreateProfileParameters, opts ...CallOption) (*ProfileResponse, error) {
+	callCtx, _, err := c.prepareCall(ctx, opFindOrCreateProfile, opts)
+	if err != nil {
+		return nil, err
+	}
+	if blockchain := c.blockchainFor(callCtx); blockchain != BlockchainSolana {
+		return nil, fmt.Errorf("tapestry: sign-in with Solana does not prove a %s wallet", blockchain)
+	}
+
+	msg, expires, err := verifier.check(proof.Message, proof.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if params.WalletAddress != "" && strings.TrimSpace(params.WalletAddress) != msg.Address {
+		return nil, &SignInError{Reason: "wallet address does not match the signed message"}
+	}
+	params.WalletAddress = msg.Address
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	if err := verifier.use(msg, expires); err != nil {
+		return nil, err
+	}
+
+	return c.FindOrCreateProfile(ctx, params, opts...)
+}
@@ -0,0 +1,216 @@
+package tapestry
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func encodeBase58(b []byte) string {
+	n := new(big.Int).SetBytes(b)
+	var out []byte
+	for mod, base := new(big.Int), big.NewInt(58); n.Sign() > 0; {
+		n.DivMod(n, base, mod)
+		out = append([]byte{base58Alphabet[mod.Int64()]}, out...)
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append([]byte{'1'}, out...)
+	}
+	return string(out)
+}
+
+// signIn returns a wallet address and a proof signed by it for domain.
+func signIn(t *testing.T, domain string, edit func(*SignInMessage)) (string, SignInProof) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := encodeBase58(pub)
+
+	msg, err := NewSignInMessage(domain, address, 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edit != nil {
+		edit(msg)
+	}
+	text := msg.String()
+	return address, SignInProof{Message: text, Signature: ed25519.Sign(priv, []byte(text))}
+}
+
+func TestSignInMessage_RoundTrip(t *testing.T) {
+	msg := &SignInMessage{
+		Domain:         "app.example.com",
+		Address:        testWallet,
+		Statement:      "Sign in to Example",
+		URI:            "https://app.example.com/login",
+		ChainID:        "mainnet",
+		Nonce:          "0123abcd",
+		IssuedAt:       time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		ExpirationTime: time.Date(2024, 5, 1, 12, 5, 0, 0, time.UTC),
+		NotBefore:      time.Date(2024, 5, 1, 12, 1, 0, 0, time.UTC),
+		RequestID:      "req-1",
+		Resources:      []string{"https://app.example.com/terms", "ipfs://bafy"},
+	}
+
+	want := "app.example.com wants you to sign in with your Solana account:\n" +
+		testWallet + "\n\nSign in to Example\n\n" +
+		"URI: https://app.example.com/login\nVersion: 1\nChain ID: mainnet\nNonce: 0123abcd\n" +
+		"Issued At: 2024-05-01T12:00:00Z\nExpiration Time: 2024-05-01T12:05:00Z\n" +
+		"Not Before: 2024-05-01T12:01:00Z\nRequest ID: req-1\n" +
+		"Resources:\n- https://app.example.com/terms\n- ipfs://bafy"
+	if got := msg.String(); got != want {
+		t.Fatalf("String() =\n%s\nwant\n%s", got, want)
+	}
+
+	parsed, err := ParseSignInMessage(want)
+	if err != nil {
+		t.Fatalf("ParseSignInMessage() error = %v", err)
+	}
+	if !reflect.DeepEqual(parsed, msg) {
+		t.Errorf("ParseSignInMessage() = %+v, want %+v", parsed, msg)
+	}
+
+	for _, bad := range []string{"", "hello", strings.Replace(want, "Version: 1", "Version: 2", 1), strings.Replace(want, "Nonce: 0123abcd\n", "", 1), want + "\nhello"} {
+		if _, err := ParseSignInMessage(bad); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("ParseSignInMessage(%q) error = %v, want a SignInError", bad, err)
+		}
+	}
+}
+
+func TestSignInVerifier(t *testing.T) {
+	verifier := NewSignInVerifier("app.example.com", nil, 0)
+
+	address, proof := signIn(t, "app.example.com", nil)
+	msg, err := verifier.Verify(proof.Message, proof.Signature)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if msg.Address != address {
+		t.Errorf("Verify() address = %s, want %s", msg.Address, address)
+	}
+	if _, err := verifier.Verify(proof.Message, proof.Signature); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("replayed Verify() error = %v, want ErrUnauthorized", err)
+	}
+
+	_, otherProof := signIn(t, "app.example.com", nil)
+	tests := []struct {
+		name  string
+		proof func() SignInProof
+	}{
+		{"other domain", func() SignInProof { _, p := signIn(t, "evil.example.com", nil); return p }},
+		{"expired", func() SignInProof {
+			_, p := signIn(t, "app.example.com", func(m *SignInMessage) { m.ExpirationTime = m.IssuedAt.Add(-time.Second) })
+			return p
+		}},
+		{"not valid yet", func() SignInProof {
+			_, p := signIn(t, "app.example.com", func(m *SignInMessage) { m.NotBefore = m.IssuedAt.Add(time.Minute) })
+			return p
+		}},
+		{"too old", func() SignInProof {
+			_, p := signIn(t, "app.example.com", func(m *SignInMessage) {
+				m.IssuedAt = m.IssuedAt.Add(-time.Hour)
+				m.ExpirationTime = time.Time{}
+			})
+			return p
+		}},
+		{"tampered", func() SignInProof {
+			_, p := signIn(t, "app.example.com", nil)
+			p.Message = strings.Replace(p.Message, "Version: 1", "Version: 1\nURI: https://evil.example.com", 1)
+			return p
+		}},
+		{"signed by another wallet", func() SignInProof {
+			_, p := signIn(t, "app.example.com", nil)
+			p.Signature = otherProof.Signature
+			return p
+		}},
+		{"short signature", func() SignInProof {
+			_, p := signIn(t, "app.example.com", nil)
+			p.Signature = p.Signature[:10]
+			return p
+		}},
+	}
+	for _, tt := range tests {
+		proof := tt.proof()
+		var signInErr *SignInError
+		if _, err := verifier.Verify(proof.Message, proof.Signature); !errors.As(err, &signInErr) {
+			t.Errorf("%s: Verify() error = %v, want a SignInError", tt.name, err)
+		}
+	}
+
+	// rejected proofs do not use up their nonce
+	if _, err := verifier.Verify(otherProof.Message, otherProof.Signature); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
+
+func TestMemoryNonceStore_Expires(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryNonceStore()
+	store.now = func() time.Time { return now }
+
+	if !store.Use("n1", now.Add(time.Minute)) || store.Use("n1", now.Add(time.Minute)) {
+		t.Fatal("nonce was accepted twice")
+	}
+
+	now = now.Add(2 * time.Minute)
+	store.Use("n2", now.Add(time.Minute))
+	if len(store.used) != 1 {
+		t.Errorf("store holds %d nonces, want the expired one dropped", len(store.used))
+	}
+}
+
+func TestVerifiedFindOrCreateProfile(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	verifier := NewSignInVerifier("app.example.com", nil, 0)
+	ctx := context.Background()
+
+	address, proof := signIn(t, "app.example.com", nil)
+	if _, err := client.VerifiedFindOrCreateProfile(ctx, verifier, proof, FindOrCreateProfileParameters{Username: "alice"}); err != nil {
+		t.Fatalf("VerifiedFindOrCreateProfile() error = %v", err)
+	}
+	if body["walletAddress"] != address {
+		t.Errorf("sent wallet %v, want the signed one %s", body["walletAddress"], address)
+	}
+
+	body = nil
+	_, proof = signIn(t, "app.example.com", nil)
+	_, err := client.VerifiedFindOrCreateProfile(ctx, verifier, proof, FindOrCreateProfileParameters{WalletAddress: testWallet, Username: "mallory"})
+	if !errors.Is(err, ErrUnauthorized) || body != nil {
+		t.Errorf("VerifiedFindOrCreateProfile() for another wallet error = %v, sent %v", err, body)
+	}
+	_, err = client.VerifiedFindOrCreateProfile(ctx, verifier, proof, FindOrCreateProfileParameters{Username: "bad name"})
+	if !errors.Is(err, ErrValidation) || body != nil {
+		t.Errorf("VerifiedFindOrCreateProfile() with an invalid username error = %v, sent %v", err, body)
+	}
+	// neither rejection used up the nonce
+	if _, err := client.VerifiedFindOrCreateProfile(ctx, verifier, proof, FindOrCreateProfileParameters{Username: "bob"}); err != nil || body == nil {
+		t.Errorf("VerifiedFindOrCreateProfile() after rejected params error = %v, sent %v", err, body)
+	}
+
+	body = nil
+
+	_, proof = signIn(t, "app.example.com", nil)
+	ethCtx := ContextWithCallOptions(ctx, WithCallBlockchain(BlockchainEthereum))
+	if _, err := client.VerifiedFindOrCreateProfile(ethCtx, verifier, proof, FindOrCreateProfileParameters{Username: "alice"}); err == nil || body != nil {
+		t.Errorf("VerifiedFindOrCreateProfile() on Ethereum error = %v, sent %v", err, body)
+	}
+}
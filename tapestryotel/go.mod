@@ -0,0 +1,23 @@
+module github.com/Access-Labs-Inc/tapestry-go/tapestryotel
+
+go 1.25.0
+
+// Builds inside this repository use the SDK next to this module.
+replace github.com/Access-Labs-Inc/tapestry-go => ../
+
+require (
+	github.com/Access-Labs-Inc/tapestry-go v0.0.0-20261017080041-300e3f0f974c
+	go.opentelemetry.io/otel v1.46.0
+	go.opentelemetry.io/otel/sdk v1.46.0
+	go.opentelemetry.io/otel/trace v1.46.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-logr/logr v1.4.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
+	go.opentelemetry.io/otel/metric v1.46.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+)
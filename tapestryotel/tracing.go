@@ -0,0 +1,118 @@
+// Package tapestryotel traces Tapestry SDK calls with OpenTelemetry.
+//
+// Register the interceptor on a client to get one client span per call and
+// W3C trace context headers on every outgoing request:
+//
+//	client, err := tapestry.New(apiKey, tapestry.WithInterceptors(tapestryotel.Interceptor()))
+package tapestryotel
+
+import (
+	"context"
+	"strconv"
+
+	tapestry "github.com/Access-Labs-Inc/tapestry-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/Access-Labs-Inc/tapestry-go/tapestryotel"
+
+// Span attribute keys set on every span.
+const (
+	AttrOperation  = attribute.Key("tapestry.operation")
+	AttrEndpoint   = attribute.Key("tapestry.endpoint")
+	AttrExecution  = attribute.Key("tapestry.execution")
+	AttrBlockchain = attribute.Key("tapestry.blockchain")
+	AttrPage       = attribute.Key("tapestry.page")
+	AttrPageSize   = attribute.Key("tapestry.page_size")
+	AttrMethod     = attribute.Key("http.request.method")
+	AttrStatusCode = attribute.Key("http.response.status_code")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// Option configures the tracing interceptor.
+type Option func(*config)
+
+// WithTracerProvider sets the provider spans are created with. It defaults to
+// the global provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithPropagator sets how the trace context is written into request headers.
+// It defaults to the global propagator.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = propagator
+	}
+}
+
+// Interceptor returns a tapestry.Interceptor that starts a span named after
+// the client method, such as "tapestry.GetContents", for every call.
+func Interceptor(opts ...Option) tapestry.Interceptor {
+	cfg := config{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.tracerProvider == nil {
+		cfg.tracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.propagator == nil {
+		cfg.propagator = otel.GetTextMapPropagator()
+	}
+	tracer := cfg.tracerProvider.Tracer(instrumentationName)
+
+	return func(ctx context.Context, call *tapestry.Call, next tapestry.Invoker) error {
+		ctx, span := tracer.Start(ctx, "tapestry."+call.ClientMethod,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(callAttributes(call)...),
+		)
+		defer span.End()
+
+		cfg.propagator.Inject(ctx, propagation.HeaderCarrier(call.Header))
+
+		err := next(ctx, call)
+
+		if call.StatusCode != 0 {
+			span.SetAttributes(AttrStatusCode.Int(call.StatusCode))
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		return err
+	}
+}
+
+func callAttributes(call *tapestry.Call) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		AttrOperation.String(call.Operation),
+		AttrMethod.String(call.Method),
+		AttrBlockchain.String(string(call.Blockchain)),
+	}
+	if call.URL != nil {
+		attrs = append(attrs, AttrEndpoint.String(call.URL.Path))
+
+		query := call.URL.Query()
+		if page, err := strconv.Atoi(query.Get("page")); err == nil {
+			attrs = append(attrs, AttrPage.Int(page))
+		}
+		if pageSize, err := strconv.Atoi(query.Get("pageSize")); err == nil {
+			attrs = append(attrs, AttrPageSize.Int(pageSize))
+		}
+	}
+	if call.Execution != "" {
+		attrs = append(attrs, AttrExecution.String(string(call.Execution)))
+	}
+	return attrs
+}
@@ -0,0 +1,138 @@
+package tapestryotel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tapestry "github.com/Access-Labs-Inc/tapestry-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*tapestry.TapestryClient, *tracetest.InMemoryExporter) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	client, err := tapestry.New("key",
+		tapestry.WithBaseURL(server.URL),
+		tapestry.WithHTTPClient(server.Client()),
+		tapestry.WithExecution(tapestry.ExecutionConfirmedParsed),
+		tapestry.WithInterceptors(Interceptor(
+			WithTracerProvider(provider),
+			WithPropagator(propagation.TraceContext{}),
+		)),
+	)
+	if err != nil {
+		t.Fatalf("tapestry.New() error = %v", err)
+	}
+	return client, exporter
+}
+
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestInterceptor_RecordsSpan(t *testing.T) {
+	var traceparent string
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		_, _ = w.Write([]byte(`{"contents":[],"page":2,"pageSize":25}`))
+	})
+
+	if _, err := client.GetContents(context.Background(), tapestry.WithPagination("2", "25")); err != nil {
+		t.Fatalf("GetContents() error = %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "tapestry.GetContents" {
+		t.Errorf("span name = %q, want %q", span.Name, "tapestry.GetContents")
+	}
+
+	attrs := attributes(span)
+	want := map[attribute.Key]attribute.Value{
+		AttrOperation:  attribute.StringValue("contents.list"),
+		AttrEndpoint:   attribute.StringValue("/contents/"),
+		AttrBlockchain: attribute.StringValue("SOLANA"),
+		AttrPage:       attribute.IntValue(2),
+		AttrPageSize:   attribute.IntValue(25),
+		AttrStatusCode: attribute.IntValue(http.StatusOK),
+	}
+	for key, value := range want {
+		if attrs[key] != value {
+			t.Errorf("attribute %s = %v, want %v", key, attrs[key].Emit(), value.Emit())
+		}
+	}
+	if _, ok := attrs[AttrExecution]; ok {
+		t.Errorf("read span carries an execution attribute")
+	}
+
+	wantParent := "00-" + span.SpanContext.TraceID().String() + "-" + span.SpanContext.SpanID().String() + "-01"
+	if traceparent != wantParent {
+		t.Errorf("traceparent = %q, want %q", traceparent, wantParent)
+	}
+}
+
+func TestInterceptor_RecordsStatusCode(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	if _, err := client.CreateComment(context.Background(), tapestry.CreateCommentOptions{ContentID: "c1", ProfileID: "p1", Text: "hi"}); err != nil {
+		t.Fatalf("CreateComment() error = %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	if attrs := attributes(spans[0]); attrs[AttrStatusCode] != attribute.IntValue(http.StatusCreated) {
+		t.Errorf("status code attribute = %v, want 201", attrs[AttrStatusCode].Emit())
+	}
+}
+
+func TestInterceptor_RecordsErrors(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	})
+
+	_, err := client.CreateComment(context.Background(), tapestry.CreateCommentOptions{ContentID: "c1", ProfileID: "p1", Text: "hi"})
+	if !errors.Is(err, tapestry.ErrConflict) {
+		t.Fatalf("CreateComment() error = %v, want ErrConflict", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	attrs := attributes(span)
+	if span.Name != "tapestry.CreateComment" || span.Status.Code != codes.Error {
+		t.Errorf("span %q status = %v, want tapestry.CreateComment with error status", span.Name, span.Status.Code)
+	}
+	if attrs[AttrStatusCode] != attribute.IntValue(http.StatusConflict) {
+		t.Errorf("status code attribute = %v, want 409", attrs[AttrStatusCode].Emit())
+	}
+	if attrs[AttrExecution] != attribute.StringValue(string(tapestry.ExecutionConfirmedParsed)) {
+		t.Errorf("execution attribute = %v, want %s", attrs[AttrExecution].Emit(), tapestry.ExecutionConfirmedParsed)
+	}
+}
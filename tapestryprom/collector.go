@@ -0,0 +1,125 @@
+// Package tapestryprom exports Tapestry SDK metrics to Prometheus.
+//
+//	collector := tapestryprom.NewCollector()
+//	prometheus.MustRegister(collector)
+//	client, err := tapestry.New(apiKey, tapestry.WithMetrics(collector))
+package tapestryprom
+
+import (
+	"time"
+
+	tapestry "github.com/Access-Labs-Inc/tapestry-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector implements tapestry.Metrics and prometheus.Collector. It exposes:
+//
+//   - tapestry_requests_total{operation, status_class}
+//   - tapestry_request_duration_seconds{operation, status_class}
+//   - tapestry_retries_total{operation}
+//   - tapestry_rate_limit_wait_seconds{operation}
+type Collector struct {
+	requests      *prometheus.CounterVec
+	duration      *prometheus.HistogramVec
+	retries       *prometheus.CounterVec
+	rateLimitWait *prometheus.HistogramVec
+}
+
+var _ tapestry.Metrics = (*Collector)(nil)
+
+type config struct {
+	namespace   string
+	constLabels prometheus.Labels
+	buckets     []float64
+}
+
+// Option configures a Collector.
+type Option func(*config)
+
+// WithNamespace replaces the default "tapestry" metric name prefix.
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		c.namespace = namespace
+	}
+}
+
+// WithConstLabels adds labels to every metric, for example to tell several
+// clients apart.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(c *config) {
+		c.constLabels = labels
+	}
+}
+
+// WithBuckets sets the latency histogram buckets in seconds.
+func WithBuckets(buckets []float64) Option {
+	return func(c *config) {
+		c.buckets = buckets
+	}
+}
+
+func NewCollector(opts ...Option) *Collector {
+	cfg := config{
+		namespace: "tapestry",
+		buckets:   prometheus.DefBuckets,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   cfg.namespace,
+			Name:        "requests_total",
+			Help:        "Tapestry SDK calls by operation and status class.",
+			ConstLabels: cfg.constLabels,
+		}, []string{"operation", "status_class"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   cfg.namespace,
+			Name:        "request_duration_seconds",
+			Help:        "Latency of Tapestry SDK calls, including retries and rate limit waits.",
+			ConstLabels: cfg.constLabels,
+			Buckets:     cfg.buckets,
+		}, []string{"operation", "status_class"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   cfg.namespace,
+			Name:        "retries_total",
+			Help:        "Retried Tapestry request attempts by operation.",
+			ConstLabels: cfg.constLabels,
+		}, []string{"operation"}),
+		rateLimitWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   cfg.namespace,
+			Name:        "rate_limit_wait_seconds",
+			Help:        "Time Tapestry requests spent waiting for the client-side rate limiter.",
+			ConstLabels: cfg.constLabels,
+			Buckets:     cfg.buckets,
+		}, []string{"operation"}),
+	}
+}
+
+func (c *Collector) ObserveCall(operation string, statusClass string, duration time.Duration) {
+	c.requests.WithLabelValues(operation, statusClass).Inc()
+	c.duration.WithLabelValues(operation, statusClass).Observe(duration.Seconds())
+}
+
+func (c *Collector) IncRetry(operation string) {
+	c.retries.WithLabelValues(operation).Inc()
+}
+
+func (c *Collector) ObserveRateLimitWait(operation string, wait time.Duration) {
+	c.rateLimitWait.WithLabelValues(operation).Observe(wait.Seconds())
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+	c.retries.Describe(ch)
+	c.rateLimitWait.Describe(ch)
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+	c.retries.Collect(ch)
+	c.rateLimitWait.Collect(ch)
+}
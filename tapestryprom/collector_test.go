@@ -0,0 +1,80 @@
+package tapestryprom
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tapestry "github.com/Access-Labs-Inc/tapestry-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector_RecordsClientCalls(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/profiles/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	collector := NewCollector()
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+
+	client, err := tapestry.New("key",
+		tapestry.WithBaseURL(server.URL),
+		tapestry.WithHTTPClient(server.Client()),
+		tapestry.WithMetrics(collector),
+		tapestry.WithRetryPolicy(tapestry.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("tapestry.New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := client.GetFollowers(ctx, "p1"); err != nil {
+		t.Fatalf("GetFollowers() error = %v", err)
+	}
+	_, _ = client.GetProfileByID(ctx, "missing")
+
+	expected := `
+# HELP tapestry_requests_total Tapestry SDK calls by operation and status class.
+# TYPE tapestry_requests_total counter
+tapestry_requests_total{operation="profiles.followers",status_class="2xx"} 1
+tapestry_requests_total{operation="profiles.get",status_class="4xx"} 1
+# HELP tapestry_retries_total Retried Tapestry request attempts by operation.
+# TYPE tapestry_retries_total counter
+tapestry_retries_total{operation="profiles.followers"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "tapestry_requests_total", "tapestry_retries_total"); err != nil {
+		t.Error(err)
+	}
+	if got := testutil.CollectAndCount(collector, "tapestry_request_duration_seconds"); got != 2 {
+		t.Errorf("duration series = %d, want 2", got)
+	}
+}
+
+func TestCollector_RateLimitWait(t *testing.T) {
+	collector := NewCollector(WithNamespace("app"), WithConstLabels(prometheus.Labels{"client": "feed"}))
+	collector.ObserveRateLimitWait("contents.batchRead", 250*time.Millisecond)
+
+	if got := testutil.CollectAndCount(collector, "app_rate_limit_wait_seconds"); got != 1 {
+		t.Errorf("rate limit wait series = %d, want 1", got)
+	}
+	problems, err := testutil.CollectAndLint(collector)
+	if err != nil || len(problems) > 0 {
+		t.Errorf("lint: %v %v", err, problems)
+	}
+}
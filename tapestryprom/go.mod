@@ -0,0 +1,23 @@
+module github.com/Access-Labs-Inc/tapestry-go/tapestryprom
+
+go 1.25.0
+
+// Builds inside this repository use the SDK next to this module.
+replace github.com/Access-Labs-Inc/tapestry-go => ../
+
+require (
+	github.com/Access-Labs-Inc/tapestry-go v0.0.0-20261017080041-300e3f0f974c
+	github.com/prometheus/client_golang v1.24.1
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/kylelemons/godebug v1.1.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_model v0.6.2 // indirect
+	github.com/prometheus/common v0.70.1 // indirect
+	github.com/prometheus/procfs v0.21.1 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
+)
@@ -0,0 +1,70 @@
+package tapestry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// WithCallAPIKey sends a single call with apiKey instead of the client's key,
+// for example to act in the namespace of another app from a shared client.
+func WithCallAPIKey(apiKey string) CallOption {
+	return func(o *callOptions) {
+		o.apiKey = apiKey
+	}
+}
+
+// WithCallBaseURL sends a single call to baseURL instead of the client's base
+// URL. Trailing slashes are removed, and a call with an invalid base URL fails
+// before it is sent.
+func WithCallBaseURL(baseURL string) CallOption {
+	return func(o *callOptions) {
+		o.baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	}
+}
+
+// WithCallBlockchain sets the blockchain of a single call, overriding the
+// client default set with WithBlockchain.
+func WithCallBlockchain(blockchain Blockchain) CallOption {
+	return func(o *callOptions) {
+		o.blockchain = blockchain
+	}
+}
+
+// apiKeyFor returns the API key to send with a call made with ctx.
+func (c *TapestryClient) apiKeyFor(ctx context.Context) string {
+	if key := callOptionsFrom(ctx).apiKey; key != "" {
+		return key
+	}
+	return c.apiKey
+}
+
+// baseURLFor returns the base URL of a call made with ctx.
+func (c *TapestryClient) baseURLFor(ctx context.Context) string {
+	if baseURL := callOptionsFrom(ctx).baseURL; baseURL != "" {
+		return baseURL
+	}
+	return c.tapestryApiBaseUrl
+}
+
+// blockchainFor returns the blockchain of a call made with ctx.
+func (c *TapestryClient) blockchainFor(ctx context.Context) Blockchain {
+	if blockchain := callOptionsFrom(ctx).blockchain; blockchain != "" {
+		return blockchain
+	}
+	return c.blockchain
+}
+
+// scopedKey prefixes a cache or coalescing key with the tenant of a call when
+// it uses another API key or base URL than the client, so that apps sharing a
+// client never see each other's responses.
+func (c *TapestryClient) scopedKey(ctx context.Context, key string) string {
+	apiKey, baseURL := c.apiKeyFor(ctx), c.baseURLFor(ctx)
+	if apiKey == c.apiKey && baseURL == c.tapestryApiBaseUrl {
+		return key
+	}
+
+	sum := sha256.Sum256([]byte(apiKey + "\x00" + baseURL))
+	return "tenant:" + hex.EncodeToString(sum[:8]) + ":" + key
+}
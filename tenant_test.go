@@ -0,0 +1,174 @@
+package tapestry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTenantServer answers every read with content titled after the API key it
+// was sent with.
+func newTenantServer(t *testing.T) *recordingServer {
+	return newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		apiKey := r.Header.Get(apiKeyHeader)
+		var ids []string
+		if strings.HasSuffix(r.URL.Path, "/contents/batch/read") {
+			_ = json.NewDecoder(r.Body).Decode(&ids)
+		}
+
+		switch {
+		case ids != nil:
+			var resp GetContentsByBatchIDsResponse
+			for _, id := range ids {
+				resp.Successful = append(resp.Successful, BatchResponseContentListItem{Content: Content{ID: id, Title: apiKey}})
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case strings.Contains(r.URL.Path, "/contents/"):
+			id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+			_ = json.NewEncoder(w).Encode(GetContentResponse{Content: Content{ID: id, Title: apiKey}})
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	})
+}
+
+// sentTo reports whether req was sent with apiKey to path.
+func sentTo(req recordedRequest, apiKey, path string) bool {
+	return req.Header.Get(apiKeyHeader) == apiKey && req.URL.Path == path
+}
+
+func TestCallOptions_Tenant(t *testing.T) {
+	server := newTenantServer(t)
+	var intercepted *Call
+	client, _ := New("key-a", WithBaseURL(server.URL+"/a"), WithHTTPClient(server.Client()), WithBlockchain("SOLANA"),
+		WithInterceptors(func(ctx context.Context, call *Call, next Invoker) error {
+			intercepted = call
+			return next(ctx, call)
+		}))
+
+	ctx := ContextWithCallOptions(context.Background(),
+		WithCallAPIKey("key-b"), WithCallBaseURL(server.URL+"/b/"), WithCallBlockchain("ETHEREUM"))
+
+	content, err := client.GetContentByID(ctx, "c1")
+	if err != nil {
+		t.Fatalf("GetContentByID() error = %v", err)
+	}
+	if got := server.last(); !sentTo(got, "key-b", "/b/contents/c1") {
+		t.Errorf("request = %s %s, want the tenant's key and base URL", got.Header.Get(apiKeyHeader), got.URL)
+	}
+	if content.Content.Title != "key-b" || intercepted.Blockchain != "ETHEREUM" {
+		t.Errorf("content %+v, blockchain %q", content.Content, intercepted.Blockchain)
+	}
+
+	// options passed to a write win over the ones in its context
+	_, err = client.FindOrCreateProfile(ctx, FindOrCreateProfileParameters{WalletAddress: testWallet, Username: "alice"}, WithCallBlockchain("POLYGON"))
+	if err != nil {
+		t.Fatalf("FindOrCreateProfile() error = %v", err)
+	}
+	var body map[string]any
+	got := server.last()
+	got.decode(t, &body)
+	if !sentTo(got, "key-b", "/b/profiles/findOrCreate") || body["blockchain"] != "POLYGON" {
+		t.Errorf("request = %s %s %v", got.Header.Get(apiKeyHeader), got.URL, body)
+	}
+
+	if _, err := client.GetContentByID(context.Background(), "c1"); err != nil {
+		t.Fatalf("GetContentByID() error = %v", err)
+	}
+	if got := server.last(); !sentTo(got, "key-a", "/a/contents/c1") {
+		t.Errorf("request without overrides = %s %s, want the client defaults", got.Header.Get(apiKeyHeader), got.URL)
+	}
+}
+
+func TestCallOptions_ContextExecution(t *testing.T) {
+	server := newTenantServer(t)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	ctx := ContextWithCallOptions(context.Background(), WithCallExecution(ExecutionConfirmedParsed))
+
+	if err := client.CreateLike(ctx, "c1", Profile{ID: "p1"}); err != nil {
+		t.Fatalf("CreateLike() error = %v", err)
+	}
+	var body map[string]any
+	server.last().decode(t, &body)
+	if body["execution"] != string(ExecutionConfirmedParsed) {
+		t.Errorf("execution = %v, want the one from the context", body["execution"])
+	}
+
+	if err := client.DeleteContent(ctx, "c1"); err != nil {
+		t.Errorf("DeleteContent() error = %v, want the context's execution mode ignored", err)
+	}
+}
+
+func TestCallOptions_InvalidBaseURL(t *testing.T) {
+	server := newTenantServer(t)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	ctx := ContextWithCallOptions(context.Background(), WithCallBaseURL("ftp://example.com"))
+
+	if _, err := client.GetContentByID(ctx, "c1"); err == nil {
+		t.Error("GetContentByID() with an invalid base URL succeeded")
+	}
+	if server.count() != 0 {
+		t.Error("request with an invalid base URL was sent")
+	}
+}
+
+func TestCallOptions_TenantsDoNotShare(t *testing.T) {
+	server := newTenantServer(t)
+	client, _ := New("key-a", WithBaseURL(server.URL), WithHTTPClient(server.Client()),
+		WithCache(NewMemoryCache(10, time.Hour)), WithContentLoader(LoaderOptions{Wait: 20 * time.Millisecond}))
+	tenantCtx := ContextWithCallOptions(context.Background(), WithCallAPIKey("key-b"))
+
+	var wg sync.WaitGroup
+	titles := make([]string, 2)
+	for i, ctx := range []context.Context{context.Background(), tenantCtx} {
+		wg.Add(1)
+		go func(i int, ctx context.Context) {
+			defer wg.Done()
+			content, err := client.GetContentByID(ctx, "c1")
+			if err != nil {
+				t.Errorf("GetContentByID() error = %v", err)
+				return
+			}
+			titles[i] = content.Content.Title
+		}(i, ctx)
+	}
+	wg.Wait()
+
+	if titles[0] != "key-a" || titles[1] != "key-b" {
+		t.Errorf("titles = %v, want each tenant's own content", titles)
+	}
+	if got := server.count(); got != 2 {
+		t.Errorf("sent %d batches, want one per tenant", got)
+	}
+
+	// both are cached now, each under its own tenant
+	for ctx, want := range map[context.Context]string{context.Background(): "key-a", tenantCtx: "key-b"} {
+		content, err := client.GetContentByID(ctx, "c1")
+		if err != nil || content.Content.Title != want {
+			t.Errorf("cached GetContentByID() = %+v, %v, want title %q", content, err, want)
+		}
+	}
+	if got := server.count(); got != 2 {
+		t.Errorf("sent %d requests, want the cached reads served locally", got)
+	}
+}
+
+func TestRedact_CallAPIKey(t *testing.T) {
+	client, _ := New("client-key")
+	ctx := ContextWithCallOptions(context.Background(), WithCallAPIKey("tenant key"))
+
+	got := client.redact(ctx, "a=client-key&b=tenant+key&c=tenant key")
+	if want := "a=REDACTED&b=REDACTED&c=REDACTED"; got != want {
+		t.Errorf("redact() = %q, want %q", got, want)
+	}
+
+	err := client.redactError(ctx, errors.New(`Get "https://x/?apiKey=tenant+key": timeout`))
+	if strings.Contains(err.Error(), "tenant") {
+		t.Errorf("redactError() = %q, still holds the key", err)
+	}
+}
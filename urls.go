@@ -0,0 +1,33 @@
+package tapestry
+
+import (
+	"net/url"
+	"strings"
+)
+
+// endpoint returns the URL of the API path made of segments under the base
+// URL, with query appended unless it is empty. Every segment is escaped as a
+// single path segment, so IDs containing '/', '?', '#' or '%' reach the
+// resource they name. An empty last segment leaves a trailing slash.
+func (c *TapestryClient) endpoint(query url.Values, segments ...string) string {
+	var b strings.Builder
+	b.WriteString(c.tapestryApiBaseUrl)
+	for _, segment := range segments {
+		b.WriteByte('/')
+		b.WriteString(escapeSegment(segment))
+	}
+	if len(query) > 0 {
+		b.WriteByte('?')
+		b.WriteString(query.Encode())
+	}
+	return b.String()
+}
+
+// escapeSegment escapes s for use as one path segment. "." and ".." are
+// escaped too, since clients and proxies would otherwise resolve them.
+func escapeSegment(s string) string {
+	if s == "." || s == ".." {
+		return strings.ReplaceAll(s, ".", "%2E")
+	}
+	return url.PathEscape(s)
+}
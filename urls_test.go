@@ -0,0 +1,134 @@
+package tapestry
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestEndpoint(t *testing.T) {
+	client, _ := New("key", WithBaseURL("https://api.example.com/api/v1/"))
+
+	tests := []struct {
+		query    url.Values
+		segments []string
+		want     string
+	}{
+		{nil, []string{"contents", ""}, "https://api.example.com/api/v1/contents/"},
+		{nil, []string{"contents", "a/b?c#d"}, "https://api.example.com/api/v1/contents/a%2Fb%3Fc%23d"},
+		{nil, []string{"contents", "100% sure"}, "https://api.example.com/api/v1/contents/100%25%20sure"},
+		{nil, []string{"comments", "..", "replies"}, "https://api.example.com/api/v1/comments/%2E%2E/replies"},
+		{nil, []string{"profiles", "."}, "https://api.example.com/api/v1/profiles/%2E"},
+		{nil, []string{"profiles", "ünï"}, "https://api.example.com/api/v1/profiles/%C3%BCn%C3%AF"},
+		{url.Values{"requestorId": {"a&b=c#d"}}, []string{"profiles", "p1", "following-who-follow"},
+			"https://api.example.com/api/v1/profiles/p1/following-who-follow?requestorId=a%26b%3Dc%23d"},
+	}
+	for _, tt := range tests {
+		if got := client.endpoint(tt.query, tt.segments...); got != tt.want {
+			t.Errorf("endpoint(%v, %q) = %s, want %s", tt.query, tt.segments, got, tt.want)
+		}
+	}
+}
+
+func TestEndpoints_EscapeIDs(t *testing.T) {
+	server := newRecordingServer(t, nil)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	ctx := context.Background()
+
+	const id = "https://example.com/post?id=1#top"
+	const escaped = "https:%2F%2Fexample.com%2Fpost%3Fid=1%23top"
+
+	calls := []struct {
+		name  string
+		call  func() error
+		path  string
+		query url.Values
+	}{
+		{"GetContentByID", func() error {
+			// the server knows no content, which GetContentByID reports as not found
+			_, err := client.GetContentByID(ctx, id)
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.Endpoint == "/contents/"+escaped {
+				return nil
+			}
+			return err
+		}, "/contents/" + escaped, nil},
+		{"UpdateContent", func() error { _, err := client.UpdateContent(ctx, id, nil); return err }, "/contents/" + escaped, nil},
+		{"DeleteContent", func() error { return client.DeleteContent(ctx, id) }, "/contents/" + escaped, nil},
+		{"GetContents", func() error {
+			_, err := client.GetContents(ctx, WithProfileID(id), WithRequestingProfileID("a&b"), WithOrderBy("created_at", GetContentsSortDirectionDesc))
+			return err
+		}, "/contents/", url.Values{"profileId": {id}, "requestingProfileId": {"a&b"}, "orderByField": {"created_at"}, "orderByDirection": {"DESC"}}},
+		{"GetCommentByID", func() error { _, err := client.GetCommentByID(ctx, id, ""); return err }, "/comments/" + escaped, nil},
+		{"GetCommentReplies", func() error {
+			_, err := client.GetCommentReplies(ctx, id, GetCommentRepliesOptions{RequestingProfileID: "p 1"})
+			return err
+		}, "/comments/" + escaped + "/replies", url.Values{"requestingProfileId": {"p 1"}}},
+		{"CreateLike", func() error { return client.CreateLike(ctx, id, Profile{ID: "p1", Username: "a+b"}) }, "/likes/" + escaped, url.Values{"username": {"a+b"}}},
+		{"GetProfileByID", func() error { _, err := client.GetProfileByID(ctx, id); return err }, "/profiles/" + escaped, nil},
+		{"GetFollowers", func() error { _, err := client.GetFollowers(ctx, id, WithPage(2, 10)); return err },
+			"/profiles/" + escaped + "/followers", url.Values{"page": {"2"}, "pageSize": {"10"}}},
+		{"GetFollowingWhoFollow", func() error { _, err := client.GetFollowingWhoFollow(ctx, "..", id); return err },
+			"/profiles/%2E%2E/following-who-follow", url.Values{"requestorId": {id}}},
+	}
+
+	for _, tt := range calls {
+		if err := tt.call(); err != nil {
+			t.Errorf("%s() error = %v", tt.name, err)
+			continue
+		}
+		got := server.last().URL
+		if got.EscapedPath() != tt.path {
+			t.Errorf("%s() path = %s, want %s", tt.name, got.EscapedPath(), tt.path)
+		}
+		if query := got.Query(); len(query) != len(tt.query) || (tt.query != nil && query.Encode() != tt.query.Encode()) {
+			t.Errorf("%s() query = %v, want %v", tt.name, query, tt.query)
+		}
+	}
+}
+
+// FuzzEndpoint checks that arbitrary IDs and query values arrive at the
+// server unchanged and in the right place.
+func FuzzEndpoint(f *testing.F) {
+	for _, seed := range [][2]string{
+		{"c1", "p1"},
+		{"a/b", "x&y=z"},
+		{"?#", "#?"},
+		{"..", "."},
+		{"%2F", "%26"},
+		{"héllo wörld", "+ +"},
+		{"\x00\xff", "\n"},
+	} {
+		f.Add(seed[0], seed[1])
+	}
+
+	server := newRecordingServer(f, nil)
+	client, _ := New("key", WithBaseURL(server.URL+"/api/v1"), WithHTTPClient(server.Client()))
+
+	f.Fuzz(func(t *testing.T, id, requestor string) {
+		_, err := client.GetCommentReplies(context.Background(), id, GetCommentRepliesOptions{RequestingProfileID: requestor})
+		if err != nil {
+			t.Fatalf("GetCommentReplies(%q) error = %v", id, err)
+		}
+
+		got := server.last().URL
+		segments := strings.Split(got.EscapedPath(), "/")
+		if len(segments) != 6 || segments[2] != "v1" || segments[3] != "comments" || segments[5] != "replies" {
+			t.Fatalf("GetCommentReplies(%q) path = %s", id, got.EscapedPath())
+		}
+		if gotID, err := url.PathUnescape(segments[4]); err != nil || gotID != id {
+			t.Errorf("server saw ID %q, want %q", gotID, id)
+		}
+
+		query := got.Query()
+		if requestor == "" {
+			if len(query) != 0 {
+				t.Errorf("query = %v, want none", query)
+			}
+		} else if query.Get("requestingProfileId") != requestor || len(query) != 1 {
+			t.Errorf("query = %v, want requestingProfileId %q", query, requestor)
+		}
+	})
+}
@@ -0,0 +1,238 @@
+package tapestry
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	maxUsernameLength    = 50
+	maxPropertyKeyLength = 64
+)
+
+// FieldError is one invalid field of a request.
+type FieldError struct {
+	// Field is the JSON name of the field, for example "username" or
+	// "properties[1].key".
+	Field   string
+	Message string
+	// Err is the underlying error, if any, for example an
+	// *InvalidWalletAddressError.
+	Err error
+}
+
+func (e FieldError) String() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError lists every invalid field of a request that was rejected
+// before it was sent. It matches ErrValidation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.String()
+	}
+	return "tapestry: invalid request: " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Unwrap returns the underlying errors of the fields, so that errors.As finds
+// them.
+func (e *ValidationError) Unwrap() []error {
+	var errs []error
+	for _, field := range e.Fields {
+		if field.Err != nil {
+			errs = append(errs, field.Err)
+		}
+	}
+	return errs
+}
+
+// validator collects the field errors of a request.
+type validator struct {
+	fields []FieldError
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	v.fields = append(v.fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+		return false
+	}
+	return true
+}
+
+func (v *validator) username(field, username string) {
+	if !v.required(field, username) {
+		return
+	}
+	if len(username) > maxUsernameLength {
+		v.add(field, "must be at most %d characters", maxUsernameLength)
+	}
+	if !onlyChars(username, "_-.") {
+		v.add(field, "must only contain letters, digits, '_', '-' and '.'")
+	}
+}
+
+func (v *validator) imageURL(field, image string) {
+	if image == "" {
+		return
+	}
+	u, err := url.Parse(image)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add(field, "must be an absolute http or https URL")
+	}
+}
+
+// propertyKeys checks that keys are set, unique, and made of letters, digits,
+// '_', '-' and '.', which separates the keys of nested structs written by
+// EncodeProperties.
+func (v *validator) propertyKeys(keys []string) {
+	seen := make(map[string]bool, len(keys))
+	for i, key := range keys {
+		field := fmt.Sprintf("properties[%d].key", i)
+		if !v.required(field, key) {
+			continue
+		}
+		if len(key) > maxPropertyKeyLength {
+			v.add(field, "must be at most %d characters", maxPropertyKeyLength)
+		}
+		if !onlyChars(key, "_-.") {
+			v.add(field, "must only contain letters, digits, '_', '-' and '.'")
+		}
+		if seen[key] {
+			v.add(field, "duplicates key %q", key)
+		}
+		seen[key] = true
+	}
+}
+
+// walletAddress returns address normalized for b. An invalid address is
+// recorded as a field error and returned unchanged; any other error is
+// returned.
+func (v *validator) walletAddress(field string, b Blockchain, address string) (string, error) {
+	normalized, err := b.NormalizeAddress(address)
+	var invalid *InvalidWalletAddressError
+	if errors.As(err, &invalid) {
+		v.fields = append(v.fields, FieldError{Field: field, Message: invalid.Reason, Err: err})
+		return address, nil
+	}
+	return normalized, err
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields}
+}
+
+// onlyChars reports whether s holds only ASCII letters, digits and the
+// characters in extra.
+func onlyChars(s, extra string) bool {
+	for _, r := range s {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		case strings.ContainsRune(extra, r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks the parameters without the wallet address, whose format
+// depends on the blockchain and is checked by FindOrCreateProfile. The
+// username is required, at most 50 characters of letters, digits, '_', '-'
+// and '.', the image an absolute http or https URL, and property keys unique
+// and at most 64 characters of the same.
+func (p FindOrCreateProfileParameters) Validate() error {
+	var v validator
+	p.validate(&v)
+	return v.err()
+}
+
+func (p FindOrCreateProfileParameters) validate(v *validator) {
+	v.username("username", p.Username)
+	v.imageURL("image", p.Image)
+	keys := make([]string, len(p.Properties))
+	for i, property := range p.Properties {
+		keys[i] = property.Key
+	}
+	v.propertyKeys(keys)
+}
+
+// Validate checks the username and image like
+// FindOrCreateProfileParameters.Validate.
+func (p UpdateProfileParameters) Validate() error {
+	var v validator
+	p.validate(&v)
+	return v.err()
+}
+
+func (p UpdateProfileParameters) validate(v *validator) {
+	v.username("username", p.Username)
+	v.imageURL("image", p.Image)
+}
+
+// Validate checks that the content, profile and text are set and the
+// property keys are valid.
+func (o CreateCommentOptions) Validate() error {
+	var v validator
+	o.validate(&v)
+	return v.err()
+}
+
+func (o CreateCommentOptions) validate(v *validator) {
+	v.required("contentId", o.ContentID)
+	v.required("profileId", o.ProfileID)
+	v.required("text", o.Text)
+	keys := make([]string, len(o.Properties))
+	for i, property := range o.Properties {
+		keys[i] = property.Key
+	}
+	v.propertyKeys(keys)
+}
+
+// Validate checks that the profile is set and the property keys are valid.
+func (r FindOrCreateContentRequest) Validate() error {
+	var v validator
+	r.validate(&v)
+	return v.err()
+}
+
+func (r FindOrCreateContentRequest) validate(v *validator) {
+	v.required("profileId", r.ProfileID)
+	keys := make([]string, len(r.Properties))
+	for i, property := range r.Properties {
+		keys[i] = property.Key
+	}
+	v.propertyKeys(keys)
+}
+
+// Validate checks that both profiles are set and differ.
+func (r FollowRequest) Validate() error {
+	var v validator
+	r.validate(&v)
+	return v.err()
+}
+
+func (r FollowRequest) validate(v *validator) {
+	start := v.required("startId", r.StartID)
+	end := v.required("endId", r.EndID)
+	if start && end && r.StartID == r.EndID {
+		v.add("endId", "a profile cannot follow itself")
+	}
+}
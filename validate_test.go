@@ -0,0 +1,98 @@
+package tapestry
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fieldNames returns the fields err reports as invalid.
+func fieldNames(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || !errors.Is(err, ErrValidation) {
+		t.Fatalf("error = %v, want a ValidationError", err)
+	}
+	names := make([]string, len(validationErr.Fields))
+	for i, field := range validationErr.Fields {
+		names[i] = field.Field
+	}
+	return names
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		wants []string
+	}{
+		{"valid profile", FindOrCreateProfileParameters{Username: "alice_01", Image: "https://example.com/a.png",
+			Properties: []ProfileProperty{{Key: "twitter", Value: "@alice"}}}.Validate(), nil},
+		{"profile", FindOrCreateProfileParameters{Username: "alice smith", Image: "/a.png",
+			Properties: []ProfileProperty{{Key: ""}, {Key: "x y"}, {Key: "x y"}}}.Validate(),
+			[]string{"username", "image", "properties[0].key", "properties[1].key", "properties[2].key", "properties[2].key"}},
+		{"long username", UpdateProfileParameters{Username: strings.Repeat("a", 51)}.Validate(), []string{"username"}},
+		{"missing username", UpdateProfileParameters{Image: "ftp://example.com/a.png"}.Validate(), []string{"username", "image"}},
+		{"valid comment", CreateCommentOptions{ContentID: "c1", ProfileID: "p1", Text: "hi"}.Validate(), nil},
+		{"comment", CreateCommentOptions{Text: "  ", Properties: []CommentProperty{{Key: strings.Repeat("k", 65)}}}.Validate(),
+			[]string{"contentId", "profileId", "text", "properties[0].key"}},
+		{"valid content", FindOrCreateContentRequest{ProfileID: "p1", Properties: []ContentProperty{{Key: "title"}}}.Validate(), nil},
+		{"content", FindOrCreateContentRequest{Properties: []ContentProperty{{Key: "title"}, {Key: "title"}}}.Validate(),
+			[]string{"profileId", "properties[1].key"}},
+		{"follow", FollowRequest{}.Validate(), []string{"startId", "endId"}},
+		{"self-follow", FollowRequest{StartID: "p1", EndID: "p1"}.Validate(), []string{"endId"}},
+	}
+
+	for _, tt := range tests {
+		if got := fieldNames(t, tt.err); !reflect.DeepEqual(got, tt.wants) {
+			t.Errorf("%s: invalid fields = %v, want %v", tt.name, got, tt.wants)
+		}
+	}
+}
+
+func TestValidate_BeforeSending(t *testing.T) {
+	client, _ := New("key", WithHTTPClient(failingDoer{}))
+	ctx := context.Background()
+
+	_, err := client.FindOrCreateProfile(ctx, FindOrCreateProfileParameters{WalletAddress: "0xabc", Username: "a b"})
+	if got := fieldNames(t, err); !reflect.DeepEqual(got, []string{"username", "walletAddress"}) {
+		t.Errorf("FindOrCreateProfile() invalid fields = %v", got)
+	}
+	var invalidWallet *InvalidWalletAddressError
+	if !errors.As(err, &invalidWallet) {
+		t.Errorf("FindOrCreateProfile() error = %v, want it to wrap the InvalidWalletAddressError", err)
+	}
+
+	calls := []struct {
+		name  string
+		err   error
+		wants []string
+	}{
+		{"UpdateProfile", client.UpdateProfile(ctx, "", UpdateProfileParameters{Username: "alice"}), []string{"id"}},
+		{"FindOrCreateContent", func() error { _, err := client.FindOrCreateContent(ctx, "", "c1", nil); return err }(), []string{"profileId"}},
+		{"CreateComment", func() error {
+			_, err := client.CreateComment(ctx, CreateCommentOptions{ContentID: "c1", ProfileID: "p1"})
+			return err
+		}(), []string{"text"}},
+		{"AddFollower", client.AddFollower(ctx, "p1", "p1"), []string{"endId"}},
+		{"RemoveFollower", client.RemoveFollower(ctx, "", "p1"), []string{"startId"}},
+	}
+	for _, tt := range calls {
+		// a call that was sent fails with failingDoer's transport error instead
+		if got := fieldNames(t, tt.err); !reflect.DeepEqual(got, tt.wants) {
+			t.Errorf("%s() invalid fields = %v, want %v", tt.name, got, tt.wants)
+		}
+	}
+}
+
+func TestValidationError_Message(t *testing.T) {
+	err := FollowRequest{StartID: "p1"}.Validate()
+	if want := "tapestry: invalid request: endId: is required"; err == nil || err.Error() != want {
+		t.Errorf("Error() = %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,129 @@
+package tapestry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultWaitMinInterval = 250 * time.Millisecond
+	defaultWaitMaxInterval = 5 * time.Second
+)
+
+// WithWaitInterval sets how often the WaitFor helpers poll: after min at
+// first, doubling up to max. Defaults to 250ms and 5s.
+func WithWaitInterval(min, max time.Duration) ClientOption {
+	return func(c *TapestryClient) {
+		c.waitPolicy = RetryPolicy{MinBackoff: min, MaxBackoff: max}
+	}
+}
+
+// poll calls check until it reports true, waiting longer between calls each
+// time. Not found errors mean the write is not visible yet and are retried,
+// any other error ends the wait. It gives up when ctx is done, wrapping the
+// last not found error along with the context's.
+func (c *TapestryClient) poll(ctx context.Context, what string, check func(ctx context.Context) (bool, error)) error {
+	policy := c.waitPolicy
+	if policy.MinBackoff <= 0 {
+		policy.MinBackoff = defaultWaitMinInterval
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultWaitMaxInterval
+	}
+
+	var last error
+	for attempt := 1; ; attempt++ {
+		done, err := check(ctx)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return waitError(ctx, what, err, last)
+		}
+		if err == nil && done {
+			return nil
+		}
+		last = err
+
+		if err := sleep(ctx, policy.backoff(attempt)); err != nil {
+			return waitError(ctx, what, err, last)
+		}
+	}
+}
+
+// waitError reports why a wait for what ended. When ctx ended it, the last
+// not found error is wrapped too.
+func waitError(ctx context.Context, what string, err, last error) error {
+	if ctx.Err() != nil && last != nil {
+		return fmt.Errorf("error waiting for %s: %w (last error: %w)", what, err, last)
+	}
+	return fmt.Errorf("error waiting for %s: %w", what, err)
+}
+
+// WaitForContent polls the content until it exists and, if until is not nil,
+// until it returns true, then returns the content read last. Use it after
+// writes made with ExecutionFastUnconfirmed or ExecutionQuickSignature, for
+// example to wait until a new comment shows in the content's SocialCounts.
+// The wait ends with ctx; give it a deadline.
+//
+// The API may answer 401 for content it does not know yet, so those answers
+// are retried like not found ones; a wait that ends with ctx wraps the last
+// of them, so a wrong API key still matches ErrUnauthorized. Reads bypass
+// the cache set with WithCache and leave it untouched.
+func (c *TapestryClient) WaitForContent(ctx context.Context, contentID string, until func(*GetContentResponse) bool) (*GetContentResponse, error) {
+	var content GetContentResponse
+	err := c.poll(ctx, "content "+contentID, func(ctx context.Context) (bool, error) {
+		var err error
+		content, err = c.fetchContent(ctx, contentID)
+		if err != nil {
+			return false, err
+		}
+		return until == nil || until(&content), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &content, nil
+}
+
+// WaitForComment polls the comment until it exists. Reads bypass the cache.
+func (c *TapestryClient) WaitForComment(ctx context.Context, commentID string) (*GetCommentByIdResponse, error) {
+	var comment GetCommentByIdResponse
+	err := c.poll(ctx, "comment "+commentID, func(ctx context.Context) (bool, error) {
+		var err error
+		comment, err = c.fetchComment(ctx, commentID)
+		return err == nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// WaitForFollow polls until startID follows endID, as written by AddFollower.
+// Every poll walks the profiles startID follows.
+func (c *TapestryClient) WaitForFollow(ctx context.Context, startID, endID string) error {
+	return c.poll(ctx, fmt.Sprintf("%s to follow %s", startID, endID), func(ctx context.Context) (bool, error) {
+		return c.isFollowing(ctx, startID, endID)
+	})
+}
+
+// WaitForUnfollow polls until startID no longer follows endID, as written by
+// RemoveFollower.
+func (c *TapestryClient) WaitForUnfollow(ctx context.Context, startID, endID string) error {
+	return c.poll(ctx, fmt.Sprintf("%s to unfollow %s", startID, endID), func(ctx context.Context) (bool, error) {
+		following, err := c.isFollowing(ctx, startID, endID)
+		return !following, err
+	})
+}
+
+func (c *TapestryClient) isFollowing(ctx context.Context, startID, endID string) (bool, error) {
+	it := c.IterateFollowing(startID, IteratorOptions{PageSize: 100})
+	for it.Next(ctx) {
+		if it.Item().ID == endID {
+			return true, nil
+		}
+	}
+	return false, it.Err()
+}
@@ -0,0 +1,177 @@
+package tapestry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// eventualServer makes writes visible from the third read on.
+func eventualServer(t *testing.T) (*httptest.Server, *int32) {
+	var reads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		visible := atomic.AddInt32(&reads, 1) >= 3
+
+		switch r.URL.Path {
+		case "/contents/c1":
+			// unknown content is an empty 200 until it is indexed
+			resp := GetContentResponse{}
+			if visible {
+				resp = GetContentResponse{Content: Content{ID: "c1"}, SocialCounts: SocialCounts{CommentCount: 1}}
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case "/contents/c2":
+			// or 401 on some deployments
+			if !visible {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(GetContentResponse{Content: Content{ID: "c2"}})
+		case "/comments/m1":
+			if !visible {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(GetCommentByIdResponse{CommentData{Comment: Comment{ID: "m1"}}})
+		case "/profiles/p1/following":
+			// p1 follows p2 instead of p3 once the writes are visible
+			resp := GetFollowingResponse{Profiles: []ProfileDetails{{ID: "p3"}}}
+			if visible {
+				resp.Profiles = []ProfileDetails{{ID: "p2"}}
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+		}
+	}))
+	return server, &reads
+}
+
+func TestWaitFor(t *testing.T) {
+	tests := []struct {
+		name string
+		wait func(ctx context.Context, client *TapestryClient) error
+	}{
+		{"content", func(ctx context.Context, client *TapestryClient) error {
+			content, err := client.WaitForContent(ctx, "c1", nil)
+			if err == nil && content.Content.ID != "c1" {
+				t.Errorf("WaitForContent() = %+v", content)
+			}
+			return err
+		}},
+		{"content condition", func(ctx context.Context, client *TapestryClient) error {
+			_, err := client.WaitForContent(ctx, "c1", func(content *GetContentResponse) bool {
+				return content.SocialCounts.CommentCount > 0
+			})
+			return err
+		}},
+		{"content unauthorized", func(ctx context.Context, client *TapestryClient) error {
+			_, err := client.WaitForContent(ctx, "c2", nil)
+			return err
+		}},
+		{"comment", func(ctx context.Context, client *TapestryClient) error {
+			_, err := client.WaitForComment(ctx, "m1")
+			return err
+		}},
+		{"follow", func(ctx context.Context, client *TapestryClient) error {
+			return client.WaitForFollow(ctx, "p1", "p2")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, reads := eventualServer(t)
+			defer server.Close()
+			client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()),
+				WithWaitInterval(time.Millisecond, 2*time.Millisecond), WithCache(NewMemoryCache(10, time.Hour)))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := tt.wait(ctx, client); err != nil {
+				t.Fatalf("wait error = %v", err)
+			}
+			if got := atomic.LoadInt32(reads); got != 3 {
+				t.Errorf("polled %d times, want 3", got)
+			}
+		})
+	}
+}
+
+func TestWaitFor_LeavesCacheAlone(t *testing.T) {
+	var reads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&reads, 1)
+		_ = json.NewEncoder(w).Encode(GetContentResponse{Content: Content{ID: "c1"}, SocialCounts: SocialCounts{LikeCount: int(n)}})
+	}))
+	defer server.Close()
+	cache := NewMemoryCache(10, time.Hour)
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()),
+		WithWaitInterval(time.Millisecond, time.Millisecond), WithCache(cache))
+	ctx := context.Background()
+
+	if _, err := client.GetContentByID(ctx, "c1"); err != nil {
+		t.Fatalf("GetContentByID() error = %v", err)
+	}
+	content, err := client.WaitForContent(ctx, "c1", func(content *GetContentResponse) bool {
+		return content.SocialCounts.LikeCount >= 3
+	})
+	if err != nil || content.SocialCounts.LikeCount != 3 {
+		t.Fatalf("WaitForContent() = %+v, %v; want the third read", content, err)
+	}
+
+	if _, ok := cache.Get(contentCacheKey("c1")); !ok {
+		t.Errorf("WaitForContent() evicted the cached content")
+	}
+}
+
+func TestWaitFor_Unfollow(t *testing.T) {
+	server, reads := eventualServer(t)
+	defer server.Close()
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithWaitInterval(time.Millisecond, time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.WaitForUnfollow(ctx, "p1", "p3"); err != nil {
+		t.Fatalf("WaitForUnfollow() error = %v", err)
+	}
+	if got := atomic.LoadInt32(reads); got != 3 {
+		t.Errorf("polled %d times, want 3", got)
+	}
+}
+
+func TestWaitFor_GivesUp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/comments/bad":
+			w.WriteHeader(http.StatusBadRequest)
+		case "/contents/denied":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithWaitInterval(time.Millisecond, time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.WaitForComment(ctx, "m1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForComment() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.WaitForContent(ctx, "denied", nil)
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("WaitForContent() error = %v, want context.DeadlineExceeded wrapping the last 401", err)
+	}
+
+	if _, err := client.WaitForComment(context.Background(), "bad"); !errors.Is(err, ErrValidation) {
+		t.Errorf("WaitForComment() error = %v, want the 400 right away", err)
+	}
+}
@@ -0,0 +1,112 @@
+package tapestry
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Blockchain is the chain a profile's wallet lives on.
+type Blockchain string
+
+const (
+	BlockchainSolana   Blockchain = "SOLANA"
+	BlockchainEthereum Blockchain = "ETHEREUM"
+)
+
+// InvalidWalletAddressError reports a wallet address that is not valid on
+// its blockchain. It matches ErrValidation.
+type InvalidWalletAddressError struct {
+	Blockchain Blockchain
+	Address    string
+	Reason     string
+}
+
+func (e *InvalidWalletAddressError) Error() string {
+	return fmt.Sprintf("tapestry: invalid %s wallet address %q: %s", e.Blockchain, e.Address, e.Reason)
+}
+
+func (e *InvalidWalletAddressError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// NormalizeAddress checks that address is a wallet address on b and returns
+// it in the form the API expects. Solana addresses must be base58 encoded
+// 32-byte public keys. Ethereum addresses must be 20 bytes of hex and are
+// returned with a "0x" prefix, keeping the case of their digits. Addresses on
+// other blockchains are only trimmed of surrounding whitespace.
+func (b Blockchain) NormalizeAddress(address string) (string, error) {
+	address = strings.TrimSpace(address)
+	invalid := func(reason string) (string, error) {
+		return "", &InvalidWalletAddressError{Blockchain: b, Address: address, Reason: reason}
+	}
+	if address == "" {
+		return invalid("empty address")
+	}
+
+	switch b {
+	case BlockchainSolana:
+		key, ok := decodeBase58(address)
+		if !ok {
+			return invalid("not base58")
+		}
+		if len(key) != 32 {
+			return invalid(fmt.Sprintf("decodes to %d bytes, want 32", len(key)))
+		}
+	case BlockchainEthereum:
+		digits := address
+		if len(digits) >= 2 && digits[0] == '0' && (digits[1] == 'x' || digits[1] == 'X') {
+			digits = digits[2:]
+		}
+		if len(digits) != 40 {
+			return invalid(fmt.Sprintf("has %d hex digits, want 40", len(digits)))
+		}
+		if _, err := hex.DecodeString(digits); err != nil {
+			return invalid("not hex")
+		}
+		address = "0x" + digits
+	}
+
+	return address, nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Digits = func() [256]int8 {
+	var digits [256]int8
+	for i := range digits {
+		digits[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		digits[base58Alphabet[i]] = int8(i)
+	}
+	return digits
+}()
+
+// decodeBase58 decodes s with the Bitcoin alphabet used by Solana.
+func decodeBase58(s string) ([]byte, bool) {
+	// every leading '1' stands for a zero byte
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+
+	// big-endian base 256 digits of the rest
+	var num []byte
+	for i := zeros; i < len(s); i++ {
+		carry := int(base58Digits[s[i]])
+		if carry < 0 {
+			return nil, false
+		}
+		for j := len(num) - 1; j >= 0; j-- {
+			carry += int(num[j]) * 58
+			num[j] = byte(carry)
+			carry >>= 8
+		}
+		for ; carry > 0; carry >>= 8 {
+			num = append([]byte{byte(carry)}, num...)
+		}
+	}
+
+	return append(make([]byte, zeros), num...), true
+}
@@ -0,0 +1,110 @@
+package tapestry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testWallet = "97QsK6DFcUZFz8tkRTcYypysyWsrGuC5CcHJuZMWAQhH"
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		blockchain Blockchain
+		address    string
+		want       string
+		wantErr    bool
+	}{
+		{BlockchainSolana, testWallet, testWallet, false},
+		{BlockchainSolana, " " + testWallet + "\n", testWallet, false},
+		{BlockchainSolana, "11111111111111111111111111111111", "11111111111111111111111111111111", false},
+		{BlockchainSolana, "", "", true},
+		{BlockchainSolana, testWallet[:40], "", true},
+		{BlockchainSolana, "97QsK6DFcUZFz8tkRTcYypysyWsrGuC5CcHJuZMWAQhHH", "", true},
+		{BlockchainSolana, "0OIl" + testWallet[4:], "", true},
+		{BlockchainSolana, "0x52908400098527886E0F7030069857D2E4169EE7", "", true},
+		{BlockchainEthereum, "0x52908400098527886E0F7030069857D2E4169EE7", "0x52908400098527886E0F7030069857D2E4169EE7", false},
+		{BlockchainEthereum, "0X52908400098527886e0f7030069857d2e4169ee7", "0x52908400098527886e0f7030069857d2e4169ee7", false},
+		{BlockchainEthereum, "52908400098527886e0f7030069857d2e4169ee7", "0x52908400098527886e0f7030069857d2e4169ee7", false},
+		{BlockchainEthereum, "0x52908400098527886e0f7030069857d2e4169e", "", true},
+		{BlockchainEthereum, "0x52908400098527886e0f7030069857d2e4169eg", "", true},
+		{BlockchainEthereum, testWallet, "", true},
+		{"POLYGON", " anything ", "anything", false},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.blockchain.NormalizeAddress(tt.address)
+		if tt.wantErr {
+			var invalid *InvalidWalletAddressError
+			if !errors.As(err, &invalid) || !errors.Is(err, ErrValidation) {
+				t.Errorf("%s.NormalizeAddress(%q) error = %v, want an InvalidWalletAddressError", tt.blockchain, tt.address, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s.NormalizeAddress(%q) = %q, %v; want %q", tt.blockchain, tt.address, got, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeBase58(t *testing.T) {
+	tests := map[string][]byte{
+		"":      {},
+		"1":     {0},
+		"11":    {0, 0},
+		"2":     {1},
+		"z":     {57},
+		"21":    {58},
+		"5R":    {1, 0},
+		"15R":   {0, 1, 0},
+		"2UzHL": {0xff, 0xff, 0xff},
+	}
+	for in, want := range tests {
+		got, ok := decodeBase58(in)
+		if !ok || string(got) != string(want) {
+			t.Errorf("decodeBase58(%q) = %v, %v; want %v", in, got, ok, want)
+		}
+	}
+}
+
+func TestFindOrCreateProfile_ValidatesWallet(t *testing.T) {
+	client, _ := New("key", WithHTTPClient(failingDoer{}))
+
+	_, err := client.FindOrCreateProfile(context.Background(), FindOrCreateProfileParameters{WalletAddress: "not a wallet", Username: "alice"})
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("FindOrCreateProfile() error = %v, want ErrValidation before sending", err)
+	}
+	_, suggestedErr := client.GetSuggestedProfiles(context.Background(), "0xabc", false)
+	if !errors.Is(suggestedErr, ErrValidation) {
+		t.Errorf("GetSuggestedProfiles() error = %v, want ErrValidation before sending", suggestedErr)
+	}
+
+	for name, err := range map[string]error{"FindOrCreateProfile": err, "GetSuggestedProfiles": suggestedErr} {
+		var validation *ValidationError
+		var invalid *InvalidWalletAddressError
+		if !errors.As(err, &validation) || len(validation.Fields) != 1 || validation.Fields[0].Field != "walletAddress" || !errors.As(err, &invalid) {
+			t.Errorf("%s() error = %#v, want a *ValidationError for walletAddress wrapping an *InvalidWalletAddressError", name, err)
+		}
+	}
+}
+
+func TestFindOrCreateProfile_NormalizesWallet(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	client, _ := New("key", WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithBlockchain(BlockchainEthereum))
+
+	_, err := client.FindOrCreateProfile(context.Background(), FindOrCreateProfileParameters{WalletAddress: " 52908400098527886E0F7030069857D2E4169EE7 ", Username: "alice"})
+	if err != nil {
+		t.Fatalf("FindOrCreateProfile() error = %v", err)
+	}
+	if body["walletAddress"] != "0x52908400098527886E0F7030069857D2E4169EE7" || body["blockchain"] != "ETHEREUM" {
+		t.Errorf("sent %v", body)
+	}
+}